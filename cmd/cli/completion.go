@@ -0,0 +1,95 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"github.com/eytan-avisror/ttsum/pkg/completion"
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/discovery"
+)
+
+// completeResourceArgs completes the apiVersion and kind arguments from discovery
+func completeResourceArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 1 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	client, err := getDiscoveryClient(kubeconfigPath)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	// clusters with unavailable aggregated APIs return partial results along with an error
+	_, lists, err := client.ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	if len(args) == 0 {
+		return completion.Filter(completion.APIVersions(lists), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+	return completion.Filter(completion.Resources(lists, args[0]), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeNamespaces completes namespace flags from the cluster namespaces
+func completeNamespaces(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	k8s, err := getKubernetesClient(kubeconfigPath)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	namespaces, err := completion.Namespaces(k8s)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return completion.Filter(namespaces, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTaintMatches completes taint match flags from the taints present on nodes
+func completeTaintMatches(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	nodeTaints, ok := completionNodeTaints()
+	if !ok {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return completion.Filter(completion.TaintExpressions(nodeTaints), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTolerationMatches completes toleration match flags with tolerations of the taints present on nodes
+func completeTolerationMatches(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	nodeTaints, ok := completionNodeTaints()
+	if !ok {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return completion.Filter(completion.TolerationExpressions(nodeTaints), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func completionNodeTaints() (map[resources.ResourceReference][]v1.Taint, bool) {
+	k8s, err := getKubernetesClient(kubeconfigPath)
+	if err != nil {
+		return nil, false
+	}
+
+	nodeTaints, err := resources.ListNodeTaints(k8s)
+	if err != nil {
+		return nil, false
+	}
+	return nodeTaints, true
+}
+
+// registerNamespaceCompletion completes the namespace flag of commands
+func registerNamespaceCompletion(cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		cobra.CheckErr(cmd.RegisterFlagCompletionFunc("namespace", completeNamespaces))
+	}
+}
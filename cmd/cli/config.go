@@ -0,0 +1,135 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/eytan-avisror/ttsum/pkg/config"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// cfg is loaded once before the root command executes, it is empty when loading failed with cfgErr
+var (
+	cfg    = &config.Config{}
+	cfgErr error
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "config shows the configuration read from the config file and TTSUM_* environment variables",
+}
+
+var configViewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "view prints the configuration in effect",
+	Long:  "For example; $ ttsum config view",
+	Run:   RunConfigViewCommand,
+}
+
+func RunConfigViewCommand(cmd *cobra.Command, args []string) {
+	out, err := yaml.Marshal(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("# %v\n", config.DefaultPath())
+	if cfgErr != nil {
+		fmt.Printf("# error: %v\n", cfgErr)
+	}
+	fmt.Printf("%s", out)
+}
+
+// loadConfig reads the config file and applies TTSUM_* environment variables
+func loadConfig() (*config.Config, error) {
+	c, err := config.Load(config.DefaultPath())
+	if err != nil {
+		return nil, err
+	}
+	if err := c.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	c.RegisterTolerationPaths()
+	return c, nil
+}
+
+// expandAlias expands an alias in the first argument, unless it names a command
+func expandAlias(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == args[0] || c.HasAlias(args[0]) {
+			return args
+		}
+	}
+	return cfg.Expand(args)
+}
+
+// applyConfigDefaults sets flags of the command which were not given to their configured defaults
+func applyConfigDefaults(cmd *cobra.Command, args []string) {
+	if cfgErr != nil {
+		if ignoresConfig(cmd) {
+			return
+		}
+		log.Fatal(cfgErr)
+	}
+
+	defaults := map[string]string{
+		"namespace":        cfg.Namespace,
+		"policy":           cfg.Policy.File,
+		"policy-configmap": cfg.Policy.ConfigMap,
+	}
+	if len(cfg.Lint.SystemNamespaces) > 0 {
+		defaults["system-namespaces"] = strings.Join(cfg.Lint.SystemNamespaces, ",")
+	}
+	if cfg.Policy.Audit {
+		defaults["audit"] = strconv.FormatBool(cfg.Policy.Audit)
+	}
+	// only commands printing tables share the output formats, graph formats differ
+	if f := cmd.Flags().Lookup("output"); f != nil && f.DefValue == outputTable {
+		defaults["output"] = cfg.Output
+	}
+
+	for name, value := range defaults {
+		f := cmd.Flags().Lookup(name)
+		if value == "" || f == nil || f.Changed {
+			continue
+		}
+		if err := cmd.Flags().Set(name, value); err != nil {
+			log.Fatalf("invalid configured %v: %v", name, err)
+		}
+	}
+}
+
+// ignoresConfig returns true for commands which run without a valid config
+func ignoresConfig(cmd *cobra.Command) bool {
+	switch {
+	case cmd == versionCmd, cmd == configViewCmd:
+		return true
+	case cmd.Name() == cobra.ShellCompRequestCmd, cmd.Name() == cobra.ShellCompNoDescRequestCmd:
+		return true
+	case cmd.HasParent() && cmd.Parent().Name() == "completion":
+		return true
+	}
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configViewCmd)
+}
@@ -0,0 +1,115 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/eytan-avisror/ttsum/pkg/coverage"
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/eytan-avisror/ttsum/pkg/taints"
+	"github.com/spf13/cobra"
+)
+
+var coverageCmd = &cobra.Command{
+	Use:   "coverage",
+	Short: "coverage summarizes the nodes workloads which should run on every node cannot run on",
+}
+
+var coverageDaemonSetsCmd = &cobra.Command{
+	Use:   "daemonsets --namespace <namespace>",
+	Short: "daemonsets summarizes the nodes each DaemonSet cannot run on, grouped by the taints responsible",
+	Long:  "For example; $ ttsum coverage daemonsets --namespace logging",
+	Run:   RunCoverageDaemonSetsCommand,
+}
+
+func RunCoverageDaemonSetsCommand(cmd *cobra.Command, args []string) {
+	k8s, err := getKubernetesClient(kubeconfigPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	daemonSets, err := coverage.ListDaemonSets(k8s, namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	nodes, err := resources.ListNodes(k8s)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	pods, err := coverage.CountPods(k8s, namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts, err := resources.ListEffectiveOptions(k8s, coverage.PodSpecs(daemonSets))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	reports := make([]coverage.Report, 0, len(daemonSets))
+	for _, ds := range daemonSets {
+		ref := resources.ResourceReference{Namespace: ds.Namespace, Name: ds.Name, Kind: "DaemonSet"}
+		reports = append(reports, coverage.Compute(ds, nodes, pods[ref], opts))
+	}
+
+	if printResults(reports) {
+		return
+	}
+
+	table := newTable([]string{"NAMESPACE", "NAME", "ELIGIBLE", "EXCLUDED", "DESIRED", "SCHEDULED", "PODS", "MISMATCH", "UNCOVERED"})
+	for _, report := range reports {
+		table.Append([]string{
+			report.Namespace,
+			report.Name,
+			fmt.Sprintf("%v/%v", len(report.Eligible), report.Nodes),
+			fmt.Sprint(len(report.Excluded)),
+			fmt.Sprint(report.DesiredNumberScheduled),
+			fmt.Sprint(report.CurrentNumberScheduled),
+			fmt.Sprint(report.Pods),
+			fmt.Sprint(report.Mismatch),
+			printUncovered(report.Uncovered),
+		})
+	}
+	table.Render()
+}
+
+// printUncovered prints the nodes of each group of uncovered nodes after the taints responsible
+func printUncovered(uncovered []coverage.Uncovered) string {
+	if len(uncovered) == 0 {
+		return "none"
+	}
+
+	lines := make([]string, 0, len(uncovered))
+	for _, u := range uncovered {
+		names := make([]string, 0, len(u.Nodes))
+		for _, node := range u.Nodes {
+			names = append(names, node.Name)
+		}
+		lines = append(lines, fmt.Sprintf("%v: %v", taints.PrintPrettySeparated(u.Taints, ","), strings.Join(names, ",")))
+	}
+	return strings.Join(lines, lineSeparator())
+}
+
+func init() {
+	rootCmd.AddCommand(coverageCmd)
+	coverageCmd.AddCommand(coverageDaemonSetsCmd)
+	coverageDaemonSetsCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Target a specific namespaces, defaults to all namespaces")
+	coverageDaemonSetsCmd.Flags().StringVarP(&outputFormat, "output", "o", outputTable, outputUsage)
+	coverageDaemonSetsCmd.Flags().StringVar(&separator, "separator", "", "Separator between groups of uncovered nodes in a cell, defaults to a newline for table, <br> for markdown and \"; \" for csv and tsv")
+	registerNamespaceCompletion(coverageDaemonSetsCmd)
+}
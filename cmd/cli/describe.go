@@ -0,0 +1,191 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eytan-avisror/ttsum/pkg/describe"
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/eytan-avisror/ttsum/pkg/taints"
+	"github.com/eytan-avisror/ttsum/pkg/tolerations"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe node <name> | <kind>/[<namespace>/]<name>",
+	Short: "describe shows the taints of a node and the workloads tolerating them, or the tolerations of a workload and the nodes they unlock",
+	Long:  "For example; $ ttsum describe node ip-10-20-30-58.ec2.internal, or $ ttsum describe deployment/kube-system/coredns, or $ ttsum describe deployment/coredns -n kube-system",
+	Run:   RunDescribeCommand,
+}
+
+func RunDescribeCommand(cmd *cobra.Command, args []string) {
+	var nodeName string
+	switch {
+	case len(args) == 2 && args[0] == "node":
+		nodeName = args[1]
+	case len(args) == 1 && strings.HasPrefix(args[0], "node/"):
+		nodeName = strings.TrimPrefix(args[0], "node/")
+	case len(args) != 1:
+		log.Fatal("must provide a node or workload e.g. ttsum describe node <name>, or ttsum describe deployment/<namespace>/<name>")
+	}
+
+	k8s, err := getKubernetesClient(kubeconfigPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if nodeName != "" {
+		node, err := resources.GetNode(k8s, nodeName)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		workloadTolerations, err := describe.ListEffectiveTolerations(k8s)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		pods, err := describe.ListNodePods(k8s, nodeName)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		printNodeDescription(describe.Node(node, workloadTolerations, pods), time.Now())
+		return
+	}
+
+	ref, err := resources.ParseReference(args[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+	if ref.Namespace == "" {
+		ref.Namespace = namespace
+	}
+	if ref.Namespace == "" {
+		ref.Namespace = metav1.NamespaceDefault
+	}
+
+	effective, err := describe.EffectiveWorkloadTolerations(k8s, ref)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	nodeTaints, err := resources.ListNodeTaints(k8s)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	printWorkloadDescription(describe.Workload(effective, nodeTaints))
+}
+
+func printNodeDescription(d describe.NodeDescription, now time.Time) {
+	fmt.Printf("Name:           %v\n", d.Name)
+	fmt.Printf("Unschedulable:  %v\n", d.Unschedulable)
+
+	keys := make([]string, 0, len(d.Labels))
+	for key := range d.Labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fmt.Print("Labels:         ")
+	if len(keys) == 0 {
+		fmt.Println("none")
+	}
+	for i, key := range keys {
+		if i > 0 {
+			fmt.Print("                ")
+		}
+		fmt.Printf("%v=%v\n", key, d.Labels[key])
+	}
+
+	fmt.Print("Taints:         ")
+	if len(d.Taints) == 0 {
+		fmt.Println("none")
+	} else {
+		fmt.Println()
+	}
+	for _, t := range d.Taints {
+		fmt.Printf("  %v [%v]\n", taints.PrintPrettyWithAge([]v1.Taint{t.Taint}, now, taints.Separator), t.Class)
+		fmt.Printf("    Tolerated by: %v\n", printReferences(t.Tolerating))
+	}
+
+	fmt.Printf("Pods:           %v\n", len(d.Pods))
+	if len(d.Pods) == 0 {
+		return
+	}
+	table := newTable([]string{"NAMESPACE", "NAME", "OWNER", "PHASE"})
+	for _, pod := range d.Pods {
+		table.Append([]string{pod.Namespace, pod.Name, pod.Owner, string(pod.Phase)})
+	}
+	table.Render()
+}
+
+func printWorkloadDescription(d describe.WorkloadDescription) {
+	fmt.Printf("Name:         %v\n", d.Name)
+	fmt.Printf("Namespace:    %v\n", d.Namespace)
+	fmt.Printf("Kind:         %v\n", d.Kind)
+	fmt.Printf("Eligible:     %v/%v nodes\n", len(d.Eligible), d.Nodes)
+
+	fmt.Print("Tolerations:  ")
+	if len(d.Tolerations) == 0 {
+		fmt.Println("none")
+	} else {
+		fmt.Println()
+	}
+	for _, t := range d.Tolerations {
+		line := tolerations.PrintPretty([]v1.Toleration{t.Toleration})
+		if t.TolerationSeconds != nil {
+			line += fmt.Sprintf(" %vs", *t.TolerationSeconds)
+		}
+		source := string(t.Source)
+		if t.Source.Implicit() {
+			source = "admission: " + source
+		}
+		fmt.Printf("  %v [%v]\n", line, source)
+		fmt.Printf("    Unlocks: %v\n", printReferences(t.Unlocks))
+	}
+
+	if len(d.Rejected) > 0 {
+		fmt.Printf("Rejected:     %v\n", tolerations.PrintPrettySeparated(d.Rejected, ", "))
+	}
+}
+
+// printReferences prints references as kind/namespace/name, nodes by name
+func printReferences(refs []resources.ResourceReference) string {
+	if len(refs) == 0 {
+		return "none"
+	}
+
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if ref.Kind == "Node" {
+			names = append(names, ref.Name)
+			continue
+		}
+		names = append(names, strings.ToLower(ref.Kind)+"/"+ref.Namespace+"/"+ref.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func init() {
+	rootCmd.AddCommand(describeCmd)
+	describeCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace of workloads given as <kind>/<name>, defaults to default")
+	registerNamespaceCompletion(describeCmd)
+}
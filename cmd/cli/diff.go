@@ -0,0 +1,141 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/eytan-avisror/ttsum/pkg/snapshot"
+	"github.com/eytan-avisror/ttsum/pkg/taints"
+	"github.com/eytan-avisror/ttsum/pkg/tolerations"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <snapshot|context|live> <snapshot|context|live>",
+	Short: "diff compares taints, tolerations and scheduling eligibility between snapshots or clusters",
+	Long:  "For example; $ ttsum diff staging prod, or $ ttsum diff before.json live, use file: or context: to disambiguate e.g. $ ttsum diff file:prod context:prod",
+	Run:   RunDiffCommand,
+}
+
+func RunDiffCommand(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		log.Fatal("must provide two snapshots or contexts e.g. ttsum diff before.json live")
+	}
+
+	a, err := loadSnapshot(args[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	b, err := loadSnapshot(args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	diff := snapshot.Compare(a, b)
+	if diff.Empty() {
+		fmt.Printf("no differences between %v and %v\n", a.Source, b.Source)
+		return
+	}
+
+	if len(diff.Nodes) > 0 {
+		fmt.Println("NODE TAINTS")
+		table := newTable([]string{"NAME", "CHANGE", "ADDED", "REMOVED", "CHANGED"})
+		for _, d := range diff.Nodes {
+			table.Append([]string{d.Name, string(d.Change), printTaints(d.Added), printTaints(d.Removed), printTaintChanges(d.Changed)})
+		}
+		table.Render()
+		fmt.Println()
+	}
+
+	if len(diff.Workloads) > 0 {
+		fmt.Println("WORKLOAD TOLERATIONS")
+		table := newTable([]string{"NAMESPACE", "KIND", "NAME", "CHANGE", "ADDED", "REMOVED", "CHANGED"})
+		for _, d := range diff.Workloads {
+			table.Append([]string{d.Namespace, d.Kind, d.Name, string(d.Change), printTolerations(d.Added), printTolerations(d.Removed), printTolerationChanges(d.Changed)})
+		}
+		table.Render()
+		fmt.Println()
+	}
+
+	if len(diff.TaintSets) > 0 {
+		fmt.Println("TAINT SETS")
+		table := newTable([]string{"TAINTS", "NODES"})
+		for _, d := range diff.TaintSets {
+			table.Append([]string{taints.PrintPretty(d.Taints), fmt.Sprintf("%v -> %v", d.Before, d.After)})
+		}
+		table.Render()
+		fmt.Println()
+	}
+
+	if len(diff.Eligibility) > 0 {
+		fmt.Println("SCHEDULING ELIGIBILITY")
+		table := newTable([]string{"NAMESPACE", "KIND", "NAME", "GAINED", "LOST", "NODES"})
+		for _, d := range diff.Eligibility {
+			table.Append([]string{d.Namespace, d.Kind, d.Name, printTaintSetKeys(d.Gained), printTaintSetKeys(d.Lost), fmt.Sprintf("%v -> %v", d.Before, d.After)})
+		}
+		table.Render()
+	}
+}
+
+func printTaints(t []v1.Taint) string {
+	if len(t) == 0 {
+		return ""
+	}
+	return taints.PrintPrettySeparated(t, cellSeparator())
+}
+
+func printTolerations(t []v1.Toleration) string {
+	if len(t) == 0 {
+		return ""
+	}
+	return tolerations.PrintPrettySeparated(t, cellSeparator())
+}
+
+func printTaintChanges(changes []snapshot.TaintChange) string {
+	lines := make([]string, 0, len(changes))
+	for _, c := range changes {
+		lines = append(lines, fmt.Sprintf("%v -> %v", taints.PrintPretty([]v1.Taint{c.From}), taints.PrintPretty([]v1.Taint{c.To})))
+	}
+	return strings.Join(lines, cellSeparator())
+}
+
+func printTolerationChanges(changes []snapshot.TolerationChange) string {
+	lines := make([]string, 0, len(changes))
+	for _, c := range changes {
+		lines = append(lines, fmt.Sprintf("%v -> %v", tolerations.PrintPretty([]v1.Toleration{c.From}), tolerations.PrintPretty([]v1.Toleration{c.To})))
+	}
+	return strings.Join(lines, cellSeparator())
+}
+
+func printTaintSetKeys(keys []string) string {
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key == "" {
+			key = "none"
+		}
+		lines = append(lines, "["+key+"]")
+	}
+	return strings.Join(lines, cellSeparator())
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Target a specific namespaces, defaults to all namespaces")
+	diffCmd.Flags().StringVar(&separator, "separator", "", "Separator between multiple values in a cell, defaults to a comma and newline")
+	registerNamespaceCompletion(diffCmd)
+}
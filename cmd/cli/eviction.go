@@ -0,0 +1,109 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/eytan-avisror/ttsum/pkg/eviction"
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/eytan-avisror/ttsum/pkg/taints"
+	"github.com/eytan-avisror/ttsum/pkg/tolerations"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+var simulateNodeFailure string
+
+var evictionTimelineCmd = &cobra.Command{
+	Use:   "eviction-timeline --namespace <namespace>",
+	Short: "eviction-timeline shows when pods on nodes with NoExecute taints are evicted according to their tolerationSeconds",
+	Long:  "For example; $ ttsum eviction-timeline, or $ ttsum eviction-timeline --simulate-node-failure ip-10-20-30-233.ec2.internal",
+	Run:   RunEvictionTimelineCommand,
+}
+
+func RunEvictionTimelineCommand(cmd *cobra.Command, args []string) {
+	k8s, err := getKubernetesClient(kubeconfigPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	nodes, err := resources.ListNodes(k8s)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	pods, err := eviction.ListPods(k8s, namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	now := time.Now()
+	if simulateNodeFailure != "" {
+		node, ok := nodes[resources.ResourceReference{Name: simulateNodeFailure, Kind: "Node"}]
+		if !ok {
+			log.Fatalf("node %v not found", simulateNodeFailure)
+		}
+
+		table := newTable([]string{"NAMESPACE", "POD", "NODE", "TAINT", "TOLERATION", "SURVIVES"})
+		for _, e := range eviction.SimulateNodeFailure(node, pods, now) {
+			survives := "forever"
+			if e.At != nil {
+				survives = duration.HumanDuration(e.At.Sub(now))
+			}
+			table.Append([]string{e.Pod.Namespace, e.Pod.Name, e.Node, printEvictionTaint(e), printEvictionToleration(e), survives})
+		}
+		table.Render()
+		return
+	}
+
+	table := newTable([]string{"NAMESPACE", "POD", "NODE", "TAINT", "TOLERATION", "EVICTION", "IN"})
+	for _, e := range eviction.Timeline(pods, nodes, now) {
+		at, in := "never", "-"
+		if e.At != nil {
+			at = e.At.UTC().Format(time.RFC3339)
+			in = "overdue"
+			if remaining := e.At.Sub(now); remaining > 0 {
+				in = duration.HumanDuration(remaining)
+			}
+		}
+		table.Append([]string{e.Pod.Namespace, e.Pod.Name, e.Node, printEvictionTaint(e), printEvictionToleration(e), at, in})
+	}
+	table.Render()
+}
+
+func printEvictionTaint(e eviction.Eviction) string {
+	return taints.PrintPretty([]v1.Taint{e.Taint})
+}
+
+// printEvictionToleration prints the toleration delaying an eviction and its tolerationSeconds
+func printEvictionToleration(e eviction.Eviction) string {
+	if e.Toleration == nil {
+		return "none"
+	}
+	tol := tolerations.PrintPretty([]v1.Toleration{*e.Toleration})
+	if e.Toleration.TolerationSeconds != nil {
+		tol += fmt.Sprintf(" %vs", *e.Toleration.TolerationSeconds)
+	}
+	return tol
+}
+
+func init() {
+	rootCmd.AddCommand(evictionTimelineCmd)
+	evictionTimelineCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Target a specific namespaces, defaults to all namespaces")
+	evictionTimelineCmd.Flags().StringVar(&simulateNodeFailure, "simulate-node-failure", "", "Show how long each pod on the node would survive if the node became unreachable now")
+	registerNamespaceCompletion(evictionTimelineCmd)
+}
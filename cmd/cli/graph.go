@@ -0,0 +1,80 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"log"
+	"os"
+
+	"github.com/eytan-avisror/ttsum/pkg/graph"
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/spf13/cobra"
+)
+
+var graphFormat string
+
+var graphCmd = &cobra.Command{
+	Use:               "graph [apiVersion kind] -o dot|mermaid",
+	Short:             "graph exports the graph of taint sets and the workloads tolerating them",
+	Long:              "For example; $ ttsum graph -o dot | dot -Tsvg > graph.svg, or $ ttsum graph apps/v1 deployments -n default -o mermaid",
+	ValidArgsFunction: completeResourceArgs,
+	Run:               RunGraphCommand,
+}
+
+func RunGraphCommand(cmd *cobra.Command, args []string) {
+	if len(args) != 0 && len(args) != 2 {
+		log.Fatal("must provide group/resource e.g. ttsum graph apps/v1 deployments, or no arguments for all workloads")
+	}
+
+	if match != "" && noMatch != "" {
+		log.Fatal("--match and --no-match are mutually exclusive arguments")
+	}
+
+	k8s, err := getKubernetesClient(kubeconfigPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	resourceTolerations := listTolerations(k8s, args)
+
+	resourceTolerations = filterTolerationMatches(resourceTolerations)
+
+	nodeTaints, err := resources.ListNodeTaints(k8s)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	g := graph.Build(nodeTaints, resourceTolerations)
+	switch graphFormat {
+	case "dot":
+		err = g.WriteDot(os.Stdout)
+	case "mermaid":
+		err = g.WriteMermaid(os.Stdout)
+	default:
+		log.Fatalf("unsupported output format %v, must be one of dot, mermaid", graphFormat)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+	graphCmd.Flags().StringVarP(&graphFormat, "output", "o", "dot", "Output format, one of dot, mermaid")
+	graphCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Target a specific namespaces, defaults to all namespaces")
+	graphCmd.Flags().StringVar(&match, "match", "", "Show resources with toleration match, must be in format Operator(key=value:effect)")
+	graphCmd.Flags().StringVar(&noMatch, "no-match", "", "Show resources without toleration match, must be in format Operator(key=value:effect)")
+	registerNamespaceCompletion(graphCmd)
+	cobra.CheckErr(graphCmd.RegisterFlagCompletionFunc("match", completeTolerationMatches))
+	cobra.CheckErr(graphCmd.RegisterFlagCompletionFunc("no-match", completeTolerationMatches))
+}
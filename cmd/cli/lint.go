@@ -0,0 +1,148 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/eytan-avisror/ttsum/pkg/lint"
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/eytan-avisror/ttsum/pkg/tolerations"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+)
+
+var systemNamespaces []string
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "lint finds risky or unused taints and tolerations",
+}
+
+var lintTolerationsCmd = &cobra.Command{
+	Use:               "tolerations [apiVersion kind] --namespace <namespace>",
+	Short:             "tolerations scores workload tolerations by breadth, and flags tolerations which match no taint",
+	Long:              "For example; $ ttsum lint tolerations, or $ ttsum lint tolerations apps/v1 deployments --namespace default",
+	ValidArgsFunction: completeResourceArgs,
+	Run:               RunLintTolerationsCommand,
+}
+
+func RunLintTolerationsCommand(cmd *cobra.Command, args []string) {
+	if len(args) != 0 && len(args) != 2 {
+		log.Fatal("must provide group/resource e.g. ttsum lint tolerations apps/v1 deployments, or no arguments for all workloads")
+	}
+
+	k8s, err := getKubernetesClient(kubeconfigPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	resourceTolerations := listTolerations(k8s, args)
+
+	nodeTaints, err := resources.ListNodeTaints(k8s)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	results := lint.Tolerations(resourceTolerations, nodeTaints, lint.Options{SystemNamespaces: systemNamespaces})
+	if printResults(results) {
+		return
+	}
+
+	table := newTable([]string{"NAMESPACE", "KIND", "NAME", "SCORE", "TOLERATION", "RULE", "MESSAGE"})
+	for _, result := range results {
+		var tols, rules, messages []string
+		for _, f := range result.Findings {
+			tols = append(tols, tolerations.PrintPretty([]v1.Toleration{f.Toleration}))
+			rules = append(rules, f.Rule)
+			messages = append(messages, f.Message)
+		}
+		table.Append([]string{
+			result.Namespace,
+			result.Kind,
+			result.Name,
+			fmt.Sprint(result.Score),
+			strings.Join(tols, lineSeparator()),
+			strings.Join(rules, lineSeparator()),
+			strings.Join(messages, lineSeparator()),
+		})
+	}
+	table.Render()
+}
+
+var lintUnusedCmd = &cobra.Command{
+	Use:   "unused",
+	Short: "unused lists node taints no workload tolerates, and tolerations for taint keys no node has",
+	Long:  "For example; $ ttsum lint unused, or $ ttsum lint unused -o jsonpath='{.unusedTaints[*].taint.key}', taints are unused when no workload in any namespace tolerates them",
+	Run:   RunLintUnusedCommand,
+}
+
+func RunLintUnusedCommand(cmd *cobra.Command, args []string) {
+	k8s, err := getKubernetesClient(kubeconfigPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// a taint tolerated only outside of --namespace is still used
+	clusterTolerations, err := resources.ListWorkloadTolerations(k8s, "")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	nodeTaints, err := resources.ListNodeTaints(k8s)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	unused := lint.UnusedTaints(nodeTaints, clusterTolerations)
+	orphaned := lint.OrphanedTolerations(resources.FilterNamespace(clusterTolerations, namespace), nodeTaints)
+
+	if printResults(lint.Unused{UnusedTaints: unused, OrphanedTolerations: orphaned}) {
+		return
+	}
+
+	fmt.Println("UNUSED TAINTS")
+	table := newTable([]string{"TAINT", "CLASS", "NODES"})
+	for _, u := range unused {
+		names := make([]string, 0, len(u.Nodes))
+		for _, node := range u.Nodes {
+			names = append(names, node.Name)
+		}
+		table.Append([]string{printTaints([]v1.Taint{u.Taint}), string(u.Class), strings.Join(names, lineSeparator())})
+	}
+	table.Render()
+	fmt.Println()
+
+	fmt.Println("ORPHANED TOLERATIONS")
+	table = newTable([]string{"NAMESPACE", "KIND", "NAME", "TOLERATION"})
+	for _, o := range orphaned {
+		table.Append([]string{o.Namespace, o.Kind, o.Name, tolerations.PrintPretty([]v1.Toleration{o.Toleration})})
+	}
+	table.Render()
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+	lintCmd.AddCommand(lintTolerationsCmd)
+	lintTolerationsCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Target a specific namespaces, defaults to all namespaces")
+	lintCmd.AddCommand(lintUnusedCmd)
+	lintUnusedCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Target a specific namespaces for orphaned tolerations, unused taints always consider all namespaces")
+	for _, c := range []*cobra.Command{lintTolerationsCmd, lintUnusedCmd} {
+		c.Flags().StringVarP(&outputFormat, "output", "o", outputTable, outputUsage)
+		c.Flags().StringVar(&separator, "separator", "", "Separator between multiple values in a cell, defaults to a newline for table, <br> for markdown and \"; \" for csv and tsv")
+	}
+	lintTolerationsCmd.Flags().StringSliceVar(&systemNamespaces, "system-namespaces", lint.DefaultSystemNamespaces, "Namespaces which may tolerate NoExecute taints without tolerationSeconds")
+	registerNamespaceCompletion(lintTolerationsCmd, lintUnusedCmd)
+}
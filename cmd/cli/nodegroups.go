@@ -0,0 +1,95 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"log"
+	"strings"
+
+	"github.com/eytan-avisror/ttsum/pkg/nodegroups"
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	listNodeGroups     bool
+	nodeGroupFiles     []string
+	nodeGroupConfigMap string
+	fromFiles          []string
+)
+
+// nodeGroupsRequested returns true if any node group source flag is set
+func nodeGroupsRequested() bool {
+	return listNodeGroups || len(nodeGroupFiles) > 0 || nodeGroupConfigMap != ""
+}
+
+// loadNodeGroups reads node groups from every source set by flags
+func loadNodeGroups(k8s dynamic.Interface) []nodegroups.NodeGroup {
+	groups := make([]nodegroups.NodeGroup, 0)
+
+	if listNodeGroups {
+		listed, err := nodegroups.List(k8s)
+		if err != nil {
+			log.Fatal(err)
+		}
+		groups = append(groups, listed...)
+	}
+
+	for _, path := range nodeGroupFiles {
+		loaded, err := nodegroups.Load(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		groups = append(groups, loaded...)
+	}
+
+	if nodeGroupConfigMap != "" {
+		ref, err := resources.ParseReference("configmap/" + nodeGroupConfigMap)
+		if err != nil || ref.Namespace == "" {
+			log.Fatal("--node-group-configmap must be in format namespace/name")
+		}
+		loaded, err := nodegroups.GetConfigMap(k8s, ref.Namespace, ref.Name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		groups = append(groups, loaded...)
+	}
+	return groups
+}
+
+// fileNodeTaints returns the taints of the virtual nodes imported from --from-file, keyed like resources.ListNodeTaints
+func fileNodeTaints() map[resources.ResourceReference][]v1.Taint {
+	groups := make([]nodegroups.NodeGroup, 0)
+	for _, path := range fromFiles {
+		loaded, err := nodegroups.Load(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		groups = append(groups, loaded...)
+	}
+	return nodegroups.TaintsMap(groups)
+}
+
+// printNodeGroups prints the names of node groups separated by the cell separator
+func printNodeGroups(groups []nodegroups.NodeGroup) string {
+	if len(groups) == 0 {
+		return "none"
+	}
+
+	names := make([]string, 0, len(groups))
+	for _, g := range groups {
+		names = append(names, strings.ToLower(g.Kind)+"/"+g.Name)
+	}
+	return strings.Join(names, cellSeparator())
+}
@@ -0,0 +1,115 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/eytan-avisror/ttsum/pkg/coverage"
+	"github.com/eytan-avisror/ttsum/pkg/lint"
+	"github.com/eytan-avisror/ttsum/pkg/report"
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var reportHTML string
+
+var reportCmd = &cobra.Command{
+	Use:   "report --html <path>",
+	Short: "report generates a self-contained HTML report of taints, tolerations, lint findings and DaemonSet coverage",
+	Long:  "For example; $ ttsum report --html out.html",
+	Run:   RunReportCommand,
+}
+
+func RunReportCommand(cmd *cobra.Command, args []string) {
+	if reportHTML == "" {
+		log.Fatal("must provide an output path e.g. ttsum report --html out.html")
+	}
+
+	k8s, err := getKubernetesClient(kubeconfigPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	nodes, err := resources.ListNodes(k8s)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	clusterTolerations, err := resources.ListWorkloadTolerations(k8s, "")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	daemonSets, err := coverage.ListDaemonSets(k8s, namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	pods, err := coverage.CountPods(k8s, namespace)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	daemonSetOptions, err := resources.ListEffectiveOptions(k8s, coverage.PodSpecs(daemonSets))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	r := report.New(report.Input{
+		Source:              reportSource(),
+		Nodes:               nodes,
+		WorkloadTolerations: resources.FilterNamespace(clusterTolerations, namespace),
+		ClusterTolerations:  clusterTolerations,
+		DaemonSets:          daemonSets,
+		DaemonSetPods:       pods,
+		DaemonSetOptions:    daemonSetOptions,
+		Lint:                lint.Options{SystemNamespaces: systemNamespaces},
+	})
+
+	f, err := os.Create(reportHTML)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := r.WriteHTML(f); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("wrote report to %v\n", reportHTML)
+}
+
+// reportSource names the kubeconfig context the report was generated from
+func reportSource() string {
+	if kubeContext != "" {
+		return kubeContext
+	}
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfigPath
+	raw, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return ""
+	}
+	return raw.CurrentContext
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.Flags().StringVar(&reportHTML, "html", "", "Path of the HTML report to write")
+	reportCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Target a specific namespaces, defaults to all namespaces")
+	reportCmd.Flags().StringSliceVar(&systemNamespaces, "system-namespaces", lint.DefaultSystemNamespaces, "Namespaces which may tolerate NoExecute taints without tolerationSeconds")
+	registerNamespaceCompletion(reportCmd)
+}
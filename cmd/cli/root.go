@@ -17,26 +17,43 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "ttsum",
-	Short: "ttsum helps summarize tainted nodes and tolerating resources",
+	Use:              "ttsum",
+	Short:            "ttsum helps summarize tainted nodes and tolerating resources",
+	PersistentPreRun: applyConfigDefaults,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	// an invalid config is reported by commands which use it, so version, completion and
+	// config view keep working
+	if c, err := loadConfig(); err != nil {
+		cfgErr = err
+	} else {
+		cfg = c
+	}
+	rootCmd.SetArgs(expandAlias(os.Args[1:]))
+
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
 
+func init() {
+	rootCmd.PersistentFlags().StringVar(&kubeconfigPath, "kubeconfig", "", "Path to kubeconfig")
+	rootCmd.PersistentFlags().StringVar(&kubeContext, "context", "", "Name of the kubeconfig context to use")
+}
+
 func getKubernetesConfig() (*rest.Config, error) {
 	var config *rest.Config
 	config, err := rest.InClusterConfig()
@@ -52,22 +69,33 @@ func getKubernetesLocalConfig() (*rest.Config, error) {
 	return clientCfg.ClientConfig()
 }
 
+func getKubernetesContextConfig(kubePath, kubeContext string) (*rest.Config, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubePath
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+	clientCfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+	return clientCfg.ClientConfig()
+}
+
+// getKubernetesContexts returns the contexts defined in the kubeconfig
+func getKubernetesContexts(kubePath string) (map[string]*clientcmdapi.Context, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubePath
+	raw, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).RawConfig()
+	if err != nil {
+		return nil, err
+	}
+	return raw.Contexts, nil
+}
+
 func getKubernetesClient(kubePath string) (dynamic.Interface, error) {
-	var (
-		config *rest.Config
-		err    error
-	)
+	return getKubernetesContextClient(kubePath, kubeContext)
+}
 
-	if kubePath == "" {
-		config, err = getKubernetesConfig()
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		config, err = clientcmd.BuildConfigFromFlags("", kubePath)
-		if err != nil {
-			return nil, err
-		}
+func getKubernetesContextClient(kubePath, kubeContext string) (dynamic.Interface, error) {
+	config, err := getKubernetesClientConfig(kubePath, kubeContext)
+	if err != nil {
+		return nil, err
 	}
 
 	client, err := dynamic.NewForConfig(config)
@@ -76,3 +104,21 @@ func getKubernetesClient(kubePath string) (dynamic.Interface, error) {
 	}
 	return client, nil
 }
+
+func getDiscoveryClient(kubePath string) (discovery.DiscoveryInterface, error) {
+	config, err := getKubernetesClientConfig(kubePath, kubeContext)
+	if err != nil {
+		return nil, err
+	}
+	return discovery.NewDiscoveryClientForConfig(config)
+}
+
+func getKubernetesClientConfig(kubePath, kubeContext string) (*rest.Config, error) {
+	if kubeContext != "" {
+		return getKubernetesContextConfig(kubePath, kubeContext)
+	}
+	if kubePath == "" {
+		return getKubernetesConfig()
+	}
+	return clientcmd.BuildConfigFromFlags("", kubePath)
+}
@@ -0,0 +1,137 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/eytan-avisror/ttsum/pkg/nodegroups"
+	"github.com/eytan-avisror/ttsum/pkg/printers"
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+var schedulableCmd = &cobra.Command{
+	Use:               "schedulable [apiVersion kind] --namespace <namespace>",
+	Short:             "schedulable summarizes the nodes each workload's tolerations allow it to be scheduled on",
+	Long:              "For example; $ ttsum schedulable apps/v1 deployments --namespace kube-system",
+	ValidArgsFunction: completeResourceArgs,
+	Run:               RunSchedulableCommand,
+}
+
+func RunSchedulableCommand(cmd *cobra.Command, args []string) {
+	if len(args) != 0 && len(args) != 2 {
+		log.Fatal("must provide group/resource e.g. ttsum schedulable apps/v1 deployments, or no arguments for all workloads")
+	}
+
+	k8s, err := getKubernetesClient(kubeconfigPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	resourceTolerations := listTolerations(k8s, args)
+
+	var nodeTaints map[resources.ResourceReference][]v1.Taint
+	if len(fromFiles) > 0 {
+		nodeTaints = fileNodeTaints()
+	} else {
+		nodeTaints, err = resources.ListNodeTaints(k8s)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	workloads := resources.TolerationsResults(resourceTolerations)
+	if printers.IsTemplate(outputFormat) {
+		results := make([]resources.SchedulableResult, 0, len(workloads))
+		for _, workload := range workloads {
+			results = append(results, resources.Schedulable(workload.ResourceReference, workload.Tolerations, nodeTaints))
+		}
+		printResults(results)
+		return
+	}
+
+	header := []string{"NAMESPACE", "KIND", "NAME", "ELIGIBLE", "UNTOLERATED TAINTS"}
+	var groups []nodegroups.NodeGroup
+	if nodeGroupsRequested() {
+		groups = loadNodeGroups(k8s)
+		header = append(header, "PROVISIONABLE")
+	}
+
+	table := newTable(header)
+	for _, workload := range workloads {
+		result := resources.Schedulable(workload.ResourceReference, workload.Tolerations, nodeTaints)
+		row := []string{
+			result.Namespace,
+			result.Kind,
+			result.Name,
+			fmt.Sprintf("%v/%v", len(result.EligibleNodes), len(nodeTaints)),
+			printTaints(distinctTaints(result.IneligibleNodes)),
+		}
+		if groups != nil {
+			row = append(row, printNodeGroups(nodegroups.Provisionable(workload.Tolerations, groups)))
+		}
+		table.Append(row)
+	}
+	table.Render()
+}
+
+// distinctTaints returns the unique taints across results, ignoring the time they were added
+func distinctTaints(results []resources.TaintsResult) []v1.Taint {
+	var (
+		seen     = make(map[v1.Taint]bool)
+		distinct = make([]v1.Taint, 0)
+	)
+	for _, result := range results {
+		for _, t := range result.Taints {
+			key := v1.Taint{Key: t.Key, Value: t.Value, Effect: t.Effect}
+			if !seen[key] {
+				seen[key] = true
+				distinct = append(distinct, key)
+			}
+		}
+	}
+	return distinct
+}
+
+// listTolerations lists the tolerations of the resource given as arguments, or of all workloads
+func listTolerations(k8s dynamic.Interface, args []string) map[resources.ResourceReference][]v1.Toleration {
+	var (
+		resourceTolerations map[resources.ResourceReference][]v1.Toleration
+		err                 error
+	)
+	if len(args) == 2 {
+		resourceTolerations, err = resources.ListResourceTolerations(k8s, resources.Parse(args[0], args[1]), namespace)
+	} else {
+		resourceTolerations, err = resources.ListWorkloadTolerations(k8s, namespace)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	return resourceTolerations
+}
+
+func init() {
+	rootCmd.AddCommand(schedulableCmd)
+	schedulableCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Target a specific namespaces, defaults to all namespaces")
+	schedulableCmd.Flags().StringVarP(&outputFormat, "output", "o", outputTable, outputUsage)
+	schedulableCmd.Flags().StringVar(&separator, "separator", "", "Separator between multiple taints in a cell, defaults to a comma and newline for table, <br> for markdown and \"; \" for csv and tsv")
+	schedulableCmd.Flags().BoolVar(&listNodeGroups, "node-groups", false, "Show the Karpenter NodePools and Cluster API MachineDeployments which could provision a node for each workload")
+	schedulableCmd.Flags().StringSliceVar(&nodeGroupFiles, "node-group-file", nil, "Read node groups from NodePool, MachineDeployment, MachineSet or cluster-autoscaler tag ConfigMap manifests")
+	schedulableCmd.Flags().StringVar(&nodeGroupConfigMap, "node-group-configmap", "", "ConfigMap holding cluster-autoscaler node template tags keyed by node group, in format namespace/name")
+	schedulableCmd.Flags().StringSliceVar(&fromFiles, "from-file", nil, "Use virtual nodes from eksctl ClusterConfigs, Terraform JSON plans, GKE or AKS node pools or node group manifests instead of cluster nodes")
+	registerNamespaceCompletion(schedulableCmd)
+}
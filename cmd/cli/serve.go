@@ -0,0 +1,118 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/eytan-avisror/ttsum/pkg/cache"
+	"github.com/eytan-avisror/ttsum/pkg/metrics"
+	"github.com/eytan-avisror/ttsum/pkg/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/cobra"
+)
+
+var (
+	metricsAddr    string
+	apiAddr        string
+	resyncInterval time.Duration
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve --metrics-addr <address> --api-addr <address>",
+	Short: "serve runs a long-lived process exporting taint and toleration metrics and a JSON API",
+	Long:  "For example; $ ttsum serve --metrics-addr :9090 --api-addr :8080",
+	Run:   RunServeCommand,
+}
+
+func RunServeCommand(cmd *cobra.Command, args []string) {
+	if metricsAddr == "" && apiAddr == "" {
+		log.Fatal("must provide --metrics-addr and/or --api-addr")
+	}
+
+	k8s, err := getKubernetesClient(kubeconfigPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	c, err := cache.New(k8s, namespace, resyncInterval)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stopCh := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-signals
+		close(stopCh)
+	}()
+
+	if err := c.Start(stopCh); err != nil {
+		log.Fatal(err)
+	}
+
+	errCh := make(chan error, 2)
+	if metricsAddr != "" {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(metrics.NewCollector(c, namespace))
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+		mux.HandleFunc("/healthz", healthz)
+		go serveHTTP(metricsAddr, mux, errCh)
+		log.Printf("serving metrics on %v", metricsAddr)
+	}
+
+	if apiAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/v1/", server.New(c))
+		mux.HandleFunc("/healthz", healthz)
+		go serveHTTP(apiAddr, mux, errCh)
+		log.Printf("serving api on %v", apiAddr)
+	}
+
+	select {
+	case err := <-errCh:
+		log.Fatal(err)
+	case <-stopCh:
+	}
+}
+
+func healthz(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func serveHTTP(addr string, handler http.Handler, errCh chan<- error) {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errCh <- srv.ListenAndServe()
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "Address to serve Prometheus metrics on, e.g. :9090")
+	serveCmd.Flags().StringVar(&apiAddr, "api-addr", "", "Address to serve the JSON API on, e.g. :8080")
+	serveCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Target a specific namespaces, defaults to all namespaces")
+	serveCmd.Flags().DurationVar(&resyncInterval, "resync", 10*time.Minute, "Informer resync interval")
+	registerNamespaceCompletion(serveCmd)
+}
@@ -0,0 +1,110 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/eytan-avisror/ttsum/pkg/snapshot"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	liveSource    = "live"
+	filePrefix    = "file:"
+	contextPrefix = "context:"
+)
+
+var snapshotFile string
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot --file <path>",
+	Short: "snapshot saves node taints and workload tolerations for a later diff",
+	Long:  "For example; $ ttsum snapshot --file prod.json",
+	Run:   RunSnapshotCommand,
+}
+
+func RunSnapshotCommand(cmd *cobra.Command, args []string) {
+	k8s, err := getKubernetesClient(kubeconfigPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	source := kubeContext
+	if source == "" {
+		source = liveSource
+	}
+
+	s, err := snapshot.Capture(k8s, namespace, source)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	out := os.Stdout
+	if snapshotFile != "" {
+		out, err = os.Create(snapshotFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer out.Close()
+	}
+
+	if err := s.Write(out); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// loadSnapshot resolves a snapshot file, the live cluster or a kubeconfig context into a snapshot,
+// sources may be prefixed with file: or context: and a context takes precedence over a file of the same name
+func loadSnapshot(source string) (*snapshot.Snapshot, error) {
+	switch {
+	case strings.HasPrefix(source, filePrefix):
+		return snapshot.Load(strings.TrimPrefix(source, filePrefix))
+	case strings.HasPrefix(source, contextPrefix):
+		kubeCtx := strings.TrimPrefix(source, contextPrefix)
+		return captureSnapshot(kubeCtx, kubeCtx)
+	case source == liveSource:
+		return captureSnapshot(kubeContext, source)
+	}
+
+	contexts, err := getKubernetesContexts(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := contexts[source]; ok {
+		return captureSnapshot(source, source)
+	}
+
+	if _, err := os.Stat(source); err != nil {
+		return nil, errors.Errorf("%v is neither a kubeconfig context nor a snapshot file", source)
+	}
+	return snapshot.Load(source)
+}
+
+func captureSnapshot(kubeCtx, source string) (*snapshot.Snapshot, error) {
+	k8s, err := getKubernetesContextClient(kubeconfigPath, kubeCtx)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Capture(k8s, namespace, source)
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.Flags().StringVarP(&snapshotFile, "file", "f", "", "Path to write the snapshot to, defaults to stdout")
+	snapshotCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Target a specific namespaces, defaults to all namespaces")
+	registerNamespaceCompletion(snapshotCmd)
+}
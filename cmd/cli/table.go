@@ -0,0 +1,186 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/eytan-avisror/ttsum/pkg/printers"
+	"github.com/olekukonko/tablewriter"
+)
+
+const (
+	outputTable    = "table"
+	outputMarkdown = "markdown"
+	outputCSV      = "csv"
+	outputTSV      = "tsv"
+
+	sortByUsage = "Comma separated sort keys, any of namespace, name, kind, taint-count and key, ties are broken by namespace, kind and name"
+	outputUsage = "Output format, one of table, markdown, csv, tsv, jsonpath=<template>, go-template=<template> or custom-columns=<spec>"
+)
+
+var (
+	outputFormat string
+	separator    string
+)
+
+// table is implemented by each output format of tabular commands
+type table interface {
+	Append(row []string)
+	AppendBulk(rows [][]string)
+	Render()
+}
+
+func newTable(header []string) table {
+	switch outputFormat {
+	case "", outputTable:
+		return newTableWriter(header)
+	case outputMarkdown:
+		return &markdownTable{out: os.Stdout, header: header}
+	case outputCSV:
+		return &delimitedTable{out: os.Stdout, header: header, comma: ','}
+	case outputTSV:
+		return &delimitedTable{out: os.Stdout, header: header, comma: '\t'}
+	default:
+		log.Fatalf("unsupported output format %q, must be one of table, markdown, csv, tsv, jsonpath=, go-template= or custom-columns=", outputFormat)
+	}
+	return nil
+}
+
+// printResults prints results with a jsonpath, go-template or custom-columns output format, it
+// returns false for other formats which are rendered as a table
+func printResults(results interface{}) bool {
+	if !printers.IsTemplate(outputFormat) {
+		return false
+	}
+
+	printer, err := printers.New(outputFormat)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := printer.Print(os.Stdout, results); err != nil {
+		log.Fatal(err)
+	}
+	return true
+}
+
+func newTableWriter(header []string) *tablewriter.Table {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetBorder(false)
+	table.SetTablePadding("\t")
+	table.SetNoWhiteSpace(true)
+	return table
+}
+
+// cellSeparator returns the separator between values of multi-valued cells, --separator accepts
+// \n and \t escapes
+func cellSeparator() string {
+	if separator != "" {
+		return strings.NewReplacer(`\n`, "\n", `\t`, "\t").Replace(separator)
+	}
+	switch outputFormat {
+	case outputMarkdown:
+		return "<br>"
+	case outputCSV, outputTSV:
+		return "; "
+	default:
+		return ",\n"
+	}
+}
+
+// lineSeparator is the cell separator of values which tables list one per line without commas
+func lineSeparator() string {
+	if separator == "" && (outputFormat == "" || outputFormat == outputTable) {
+		return "\n"
+	}
+	return cellSeparator()
+}
+
+// markdownTable renders rows as a GitHub flavored markdown table
+type markdownTable struct {
+	out    io.Writer
+	header []string
+	rows   [][]string
+}
+
+func (t *markdownTable) Append(row []string) {
+	t.rows = append(t.rows, row)
+}
+
+func (t *markdownTable) AppendBulk(rows [][]string) {
+	t.rows = append(t.rows, rows...)
+}
+
+func (t *markdownTable) Render() {
+	dividers := make([]string, len(t.header))
+	for i := range dividers {
+		dividers[i] = "---"
+	}
+	t.writeRow(t.header)
+	t.writeRow(dividers)
+	for _, row := range t.rows {
+		t.writeRow(row)
+	}
+}
+
+func (t *markdownTable) writeRow(row []string) {
+	cells := make([]string, 0, len(row))
+	for _, cell := range row {
+		cells = append(cells, markdownEscaper.Replace(cell))
+	}
+	fmt.Fprintf(t.out, "| %v |\n", strings.Join(cells, " | "))
+}
+
+// markdownEscaper keeps cells on a single line and escapes the column delimiter
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\n", "<br>")
+
+// delimitedTable renders rows as CSV, or TSV when comma is a tab
+type delimitedTable struct {
+	out    io.Writer
+	header []string
+	comma  rune
+	rows   [][]string
+}
+
+func (t *delimitedTable) Append(row []string) {
+	t.rows = append(t.rows, row)
+}
+
+func (t *delimitedTable) AppendBulk(rows [][]string) {
+	t.rows = append(t.rows, rows...)
+}
+
+func (t *delimitedTable) Render() {
+	w := csv.NewWriter(t.out)
+	w.Comma = t.comma
+	if err := w.Write(t.header); err != nil {
+		log.Fatal(err)
+	}
+	if err := w.WriteAll(t.rows); err != nil {
+		log.Fatal(err)
+	}
+}
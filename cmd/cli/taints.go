@@ -14,14 +14,23 @@ package cli
 
 import (
 	"log"
-	"os"
-	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/eytan-avisror/ttsum/pkg/resources"
 	"github.com/eytan-avisror/ttsum/pkg/taints"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	explain    bool
+	userOnly   bool
+	conditions bool
+
+	taintsSortBy []string
 )
 
 var taintCmd = &cobra.Command{
@@ -36,14 +45,31 @@ func RunTaintsCommand(cmd *cobra.Command, args []string) {
 		log.Fatal("--match and --no-match are mutually exclusive arguments")
 	}
 
-	k8s, err := getKubernetesClient(kubeconfigPath)
-	if err != nil {
-		log.Fatal(err)
+	if len(fromFiles) > 0 && conditions {
+		log.Fatal("--conditions requires cluster nodes and cannot be used with --from-file")
 	}
 
-	resourceTaints, err := resources.ListNodeTaints(k8s)
-	if err != nil {
-		log.Fatal(err)
+	if explain && conditions {
+		log.Fatal("--explain and --conditions are mutually exclusive arguments")
+	}
+
+	var (
+		k8s            dynamic.Interface
+		resourceTaints map[resources.ResourceReference][]v1.Taint
+		err            error
+	)
+	if len(fromFiles) > 0 {
+		resourceTaints = fileNodeTaints()
+	} else {
+		k8s, err = getKubernetesClient(kubeconfigPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		resourceTaints, err = resources.ListNodeTaints(k8s)
+		if err != nil {
+			log.Fatal(err)
+		}
 	}
 
 	if match != "" {
@@ -62,9 +88,15 @@ func RunTaintsCommand(cmd *cobra.Command, args []string) {
 		resourceTaints = resources.FilterTaints(resourceTaints, expr, false)
 	}
 
-	results := make([]TaintsResult, 0)
+	if userOnly {
+		for ref, rawTaints := range resourceTaints {
+			resourceTaints[ref] = taints.UserOnly(rawTaints)
+		}
+	}
+
+	results := make([]resources.TaintsResult, 0)
 	for resource, rawTaints := range resourceTaints {
-		results = append(results, TaintsResult{
+		results = append(results, resources.TaintsResult{
 			ResourceReference: resources.ResourceReference{
 				Name:      resource.Name,
 				Namespace: resource.Namespace,
@@ -73,40 +105,100 @@ func RunTaintsCommand(cmd *cobra.Command, args []string) {
 		})
 	}
 
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Name < results[j].Name
-	})
-
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"NAME", "TAINTS"})
-	table.SetAutoWrapText(false)
-	table.SetAutoFormatHeaders(true)
-	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
-	table.SetAlignment(tablewriter.ALIGN_LEFT)
-	table.SetCenterSeparator("")
-	table.SetColumnSeparator("")
-	table.SetRowSeparator("")
-	table.SetHeaderLine(false)
-	table.SetBorder(false)
-	table.SetTablePadding("\t")
-	table.SetNoWhiteSpace(true)
+	if err := resources.SortTaintsResults(results, taintsSortBy, reverseSort); err != nil {
+		log.Fatal(err)
+	}
+
+	if printResults(results) {
+		return
+	}
+
+	if conditions {
+		printNodeConditions(k8s, results)
+		return
+	}
+
+	if explain {
+		table := newTable([]string{"NAME", "TAINTS", "CLASS", "EXPLANATION"})
+		for _, result := range results {
+			classes, explanations := explainTaints(result.Taints)
+			table.Append([]string{result.Name, taints.PrintPrettySeparated(result.Taints, cellSeparator()), classes, explanations})
+		}
+		table.Render()
+		return
+	}
+
+	table := newTable([]string{"NAME", "TAINTS"})
 	data := make([][]string, 0)
 
 	for _, result := range results {
-		data = append(data, []string{result.Name, taints.PrintPretty(result.Taints)})
+		data = append(data, []string{result.Name, taints.PrintPrettySeparated(result.Taints, cellSeparator())})
 	}
 
 	table.AppendBulk(data)
 	table.Render()
 }
 
-type TaintsResult struct {
-	resources.ResourceReference
-	Taints []v1.Taint
+// printNodeConditions prints the taints of nodes next to their conditions
+func printNodeConditions(k8s dynamic.Interface, results []resources.TaintsResult) {
+	nodes, err := resources.ListNodes(k8s)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var (
+		now            = time.Now()
+		conditionTypes = []v1.NodeConditionType{v1.NodeReady, v1.NodeMemoryPressure, v1.NodeDiskPressure, v1.NodePIDPressure, v1.NodeNetworkUnavailable}
+		header         = []string{"NAME", "TAINTS"}
+	)
+	for _, c := range conditionTypes {
+		header = append(header, strings.ToUpper(string(c)))
+	}
+	table := newTable(append(header, "UNSCHEDULABLE", "MISMATCHES"))
+
+	for _, result := range results {
+		node := nodes[resources.ResourceReference{Name: result.Name, Kind: "Node"}]
+
+		row := []string{result.Name, taints.PrintPrettyWithAge(result.Taints, now, cellSeparator())}
+		for _, c := range conditionTypes {
+			row = append(row, string(resources.ConditionStatus(node, c)))
+		}
+
+		mismatches := "none"
+		if m := resources.ConditionMismatches(node); len(m) > 0 {
+			mismatches = strings.Join(m, cellSeparator())
+		}
+		table.Append(append(row, strconv.FormatBool(node.Spec.Unschedulable), mismatches))
+	}
+	table.Render()
+}
+
+// explainTaints returns the catalog class and explanation of each taint, one per line of the taints cell
+func explainTaints(ts []v1.Taint) (string, string) {
+	var (
+		classes      = make([]string, 0, len(ts))
+		explanations = make([]string, 0, len(ts))
+	)
+	for _, t := range ts {
+		entry := taints.Explain(t)
+		classes = append(classes, string(entry.Class))
+		explanations = append(explanations, entry.Explanation)
+	}
+	return strings.Join(classes, lineSeparator()), strings.Join(explanations, lineSeparator())
 }
 
 func init() {
 	rootCmd.AddCommand(taintCmd)
 	taintCmd.Flags().StringVar(&match, "match", "", "Show resources with toleration match, must be in format Operator(key=value:effect)")
 	taintCmd.Flags().StringVar(&noMatch, "no-match", "", "Show resources without toleration match, must be in format Operator(key=value:effect)")
+	taintCmd.Flags().BoolVar(&explain, "explain", false, "Show the class and an explanation of well-known taints")
+	taintCmd.Flags().BoolVar(&userOnly, "user-only", false, "Hide system and lifecycle taints")
+	taintCmd.Flags().BoolVar(&conditions, "conditions", false, "Show node conditions and cordon status next to taints and their age, along with mismatches between conditions and taints")
+	taintCmd.Flags().StringVarP(&outputFormat, "output", "o", outputTable, outputUsage)
+	taintCmd.Flags().StringVar(&separator, "separator", "", "Separator between multiple taints in a cell, defaults to a comma and newline for table, <br> for markdown and \"; \" for csv and tsv")
+	taintCmd.Flags().StringSliceVar(&taintsSortBy, "sort-by", []string{resources.SortName}, sortByUsage)
+	taintCmd.Flags().BoolVar(&reverseSort, "reverse", false, "Reverse the sort order")
+	taintCmd.Flags().StringSliceVar(&fromFiles, "from-file", nil, "Use virtual nodes from eksctl ClusterConfigs, Terraform JSON plans, GKE or AKS node pools or node group manifests instead of cluster nodes")
+	cobra.CheckErr(taintCmd.RegisterFlagCompletionFunc("match", completeTaintMatches))
+	cobra.CheckErr(taintCmd.RegisterFlagCompletionFunc("no-match", completeTaintMatches))
 }
@@ -13,115 +13,195 @@ limitations under the License.
 package cli
 
 import (
+	"fmt"
 	"log"
-	"os"
-	"sort"
+	"strings"
 
 	"github.com/eytan-avisror/ttsum/pkg/resources"
 	"github.com/eytan-avisror/ttsum/pkg/tolerations"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	v1 "k8s.io/api/core/v1"
 )
 
 var (
-	kubeconfigPath string
-	namespace      string
-	match          string
-	noMatch        string
+	kubeconfigPath  string
+	kubeContext     string
+	namespace       string
+	match           string
+	noMatch         string
+	namespacePolicy bool
+	effectiveFlag   bool
+	allWorkloads    bool
+
+	tolerationsSortBy []string
+	reverseSort       bool
 )
 
 var tolerationsCmd = &cobra.Command{
-	Use:   "tolerations [apiVersion kind] --namespace <namespace>",
-	Short: "tolerations summarizes tolerations for a resource",
-	Long:  "For example; $ ttsum tolerations apps/v1 deployment --namespace kube-system",
-	Run:   RunTolerationsCommand,
+	Use:               "tolerations [apiVersion kind | --all-workloads] --namespace <namespace>",
+	Short:             "tolerations summarizes tolerations for a resource",
+	Long:              "For example; $ ttsum tolerations apps/v1 deployment --namespace kube-system, or $ ttsum tolerations --all-workloads --match Exists(nvidia.com/gpu)",
+	ValidArgsFunction: completeResourceArgs,
+	Run:               RunTolerationsCommand,
 }
 
 func RunTolerationsCommand(cmd *cobra.Command, args []string) {
-	if len(args) != 2 {
-		log.Fatal("must provide group/resource e.g. ttsum tolerations apps/v1 deployments")
+	if allWorkloads && len(args) != 0 {
+		log.Fatal("--all-workloads does not take group/resource arguments")
+	}
+	if !allWorkloads && len(args) != 2 {
+		log.Fatal("must provide group/resource e.g. ttsum tolerations apps/v1 deployments, or --all-workloads")
 	}
 
 	if match != "" && noMatch != "" {
 		log.Fatal("--match and --no-match are mutually exclusive arguments")
 	}
 
-	gvr := resources.Parse(args[0], args[1])
-
 	k8s, err := getKubernetesClient(kubeconfigPath)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	resourceTolerations, err := resources.ListResourceTolerations(k8s, gvr, namespace)
+	var (
+		resourceTolerations map[resources.ResourceReference][]v1.Toleration
+		specs               map[resources.ResourceReference]v1.PodSpec
+	)
+	if allWorkloads {
+		resourceTolerations, specs, err = resources.ListWorkloadTemplates(k8s, namespace)
+	} else {
+		resourceTolerations, specs, err = resources.ListResourceTemplates(k8s, resources.Parse(args[0], args[1]), namespace)
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	if match != "" {
-		expr, err := tolerations.Parse(match)
-		if err != nil {
-			log.Fatal(err)
-		}
+	opts := resources.EffectiveOptions{
+		Implicit: effectiveFlag,
+		PodSpecs: specs,
+	}
+	opts.RuntimeClasses, err = resources.ListRuntimeClassTolerations(k8s, specs)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-		resourceTolerations = resources.FilterTolerations(resourceTolerations, expr, true)
-	} else if noMatch != "" {
-		expr, err := tolerations.Parse(noMatch)
+	if namespacePolicy || effectiveFlag {
+		opts.Namespaces, err = resources.ListNamespaceTolerations(k8s)
 		if err != nil {
 			log.Fatal(err)
 		}
+	}
 
-		resourceTolerations = resources.FilterTolerations(resourceTolerations, expr, false)
+	effective := resources.EffectiveTolerations(resourceTolerations, opts)
+	for ref, result := range effective {
+		resourceTolerations[ref] = resources.PlainTolerations(result.Tolerations)
 	}
 
-	results := make([]TolerationsResult, 0)
+	resourceTolerations = filterTolerationMatches(resourceTolerations)
+
+	results := make([]resources.TolerationsResult, 0)
 	for resource, rawTolerations := range resourceTolerations {
-		results = append(results, TolerationsResult{
-			ResourceReference: resources.ResourceReference{
-				Name:      resource.Name,
-				Namespace: resource.Namespace,
-			},
-			Tolerations: rawTolerations,
+		results = append(results, resources.TolerationsResult{
+			ResourceReference: resource,
+			Tolerations:       rawTolerations,
 		})
 	}
 
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Namespace < results[j].Namespace
-	})
-
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"NAMESPACE", "NAME", "TOLERATIONS"})
-	table.SetAutoWrapText(false)
-	table.SetAutoFormatHeaders(true)
-	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
-	table.SetAlignment(tablewriter.ALIGN_LEFT)
-	table.SetCenterSeparator("")
-	table.SetColumnSeparator("")
-	table.SetRowSeparator("")
-	table.SetHeaderLine(false)
-	table.SetBorder(false)
-	table.SetTablePadding("\t")
-	table.SetNoWhiteSpace(true)
+	if err := resources.SortTolerationsResults(results, tolerationsSortBy, reverseSort); err != nil {
+		log.Fatal(err)
+	}
+
+	if printResults(results) {
+		return
+	}
+
+	if namespacePolicy || effectiveFlag {
+		table := newTable([]string{"NAMESPACE", "NAME", "TOLERATIONS", "REJECTED"})
+		for _, result := range results {
+			e := effective[result.ResourceReference]
+			table.Append([]string{result.Namespace, workloadName(result.ResourceReference), printEffectiveTolerations(e.Tolerations), printTolerations(e.Rejected)})
+		}
+		table.Render()
+		return
+	}
+
+	table := newTable([]string{"NAMESPACE", "NAME", "TOLERATIONS"})
 	data := make([][]string, 0)
 
 	for _, result := range results {
-		data = append(data, []string{result.Namespace, result.Name, tolerations.PrintPretty(result.Tolerations)})
+		data = append(data, []string{result.Namespace, workloadName(result.ResourceReference), printEffectiveTolerations(effective[result.ResourceReference].Tolerations)})
 	}
 
 	table.AppendBulk(data)
 	table.Render()
 }
 
-type TolerationsResult struct {
-	resources.ResourceReference
-	Tolerations []v1.Toleration
+// workloadName prints the name of a workload, prefixed by its kind when --all-workloads lists
+// several kinds
+func workloadName(ref resources.ResourceReference) string {
+	if allWorkloads {
+		return strings.ToLower(ref.Kind) + "/" + ref.Name
+	}
+	return ref.Name
+}
+
+// filterTolerationMatches keeps the resources with a toleration matching --match, or without one
+// matching --no-match
+func filterTolerationMatches(resourceTolerations map[resources.ResourceReference][]v1.Toleration) map[resources.ResourceReference][]v1.Toleration {
+	if match != "" {
+		expr, err := tolerations.Parse(match)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return resources.FilterTolerations(resourceTolerations, expr, true)
+	}
+
+	if noMatch != "" {
+		expr, err := tolerations.Parse(noMatch)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return resources.FilterTolerations(resourceTolerations, expr, false)
+	}
+	return resourceTolerations
+}
+
+// printEffectiveTolerations prints tolerations like tolerations.PrintPretty separated by the cell
+// separator, followed by their toleration seconds and source unless they come from the template
+func printEffectiveTolerations(effective []resources.EffectiveToleration) string {
+	if len(effective) == 0 {
+		return tolerations.PrintPretty(nil)
+	}
+
+	lines := make([]string, 0, len(effective))
+	for _, t := range effective {
+		line := tolerations.PrintPretty([]v1.Toleration{t.Toleration})
+		if t.TolerationSeconds != nil {
+			line += fmt.Sprintf(" %vs", *t.TolerationSeconds)
+		}
+		switch {
+		case t.Source.Implicit():
+			line += fmt.Sprintf(" [implicit: %v]", t.Source)
+		case t.Source != resources.SourceTemplate:
+			line += fmt.Sprintf(" [%v]", t.Source)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, cellSeparator())
 }
 
 func init() {
 	rootCmd.AddCommand(tolerationsCmd)
-	tolerationsCmd.Flags().StringVar(&kubeconfigPath, "kubeconfig", "", "Path to kubeconfig")
 	tolerationsCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Target a specific namespaces, defaults to all namespaces")
 	tolerationsCmd.Flags().StringVar(&match, "match", "", "Show resources with toleration match, must be in format Operator(key=value:effect)")
 	tolerationsCmd.Flags().StringVar(&noMatch, "no-match", "", "Show resources without toleration match, must be in format Operator(key=value:effect)")
+	tolerationsCmd.Flags().BoolVar(&namespacePolicy, "namespace-policy", false, "Merge PodTolerationRestriction namespace default tolerations and show tolerations rejected by the namespace whitelist")
+	tolerationsCmd.Flags().StringVarP(&outputFormat, "output", "o", outputTable, outputUsage)
+	tolerationsCmd.Flags().StringVar(&separator, "separator", "", "Separator between multiple tolerations in a cell, defaults to a comma and newline for table, <br> for markdown and \"; \" for csv and tsv")
+	tolerationsCmd.Flags().StringSliceVar(&tolerationsSortBy, "sort-by", []string{resources.SortNamespace, resources.SortName}, sortByUsage)
+	tolerationsCmd.Flags().BoolVar(&reverseSort, "reverse", false, "Reverse the sort order")
+	tolerationsCmd.Flags().BoolVar(&allWorkloads, "all-workloads", false, "Show the tolerations of all workloads, deployments, statefulsets, daemonsets, jobs, cronjobs and configured workload toleration paths, instead of a single group/resource")
+	tolerationsCmd.Flags().BoolVar(&effectiveFlag, "effective", false, "Show the tolerations pods are admitted with, including implicit DefaultTolerationSeconds and DaemonSet controller tolerations, implies --namespace-policy")
+	registerNamespaceCompletion(tolerationsCmd)
+	cobra.CheckErr(tolerationsCmd.RegisterFlagCompletionFunc("match", completeTolerationMatches))
+	cobra.CheckErr(tolerationsCmd.RegisterFlagCompletionFunc("no-match", completeTolerationMatches))
 }
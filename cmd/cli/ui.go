@@ -0,0 +1,83 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"log"
+	"time"
+
+	"github.com/eytan-avisror/ttsum/pkg/cache"
+	"github.com/eytan-avisror/ttsum/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+// uiRefreshInterval is the minimum time between refreshes caused by cluster changes
+const uiRefreshInterval = 2 * time.Second
+
+var uiCmd = &cobra.Command{
+	Use:   "ui --namespace <namespace>",
+	Short: "ui browses taint sets, their nodes and the workloads tolerating them in an interactive terminal UI",
+	Long:  "For example; $ ttsum ui --namespace kube-system",
+	Run:   RunUICommand,
+}
+
+func RunUICommand(cmd *cobra.Command, args []string) {
+	k8s, err := getKubernetesClient(kubeconfigPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	c, err := cache.New(k8s, namespace, resyncInterval)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	changed := make(chan struct{}, 1)
+	c.AddEventHandler(func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+
+	if err := c.Start(stopCh); err != nil {
+		log.Fatal(err)
+	}
+
+	u := ui.New(c, namespace)
+	go func() {
+		for {
+			select {
+			case <-changed:
+				u.Refresh()
+				time.Sleep(uiRefreshInterval)
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+
+	if err := u.Run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+	uiCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Target a specific namespaces, defaults to all namespaces")
+	uiCmd.Flags().DurationVar(&resyncInterval, "resync", 10*time.Minute, "Informer resync interval")
+	registerNamespaceCompletion(uiCmd)
+}
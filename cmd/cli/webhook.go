@@ -0,0 +1,102 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/eytan-avisror/ttsum/pkg/policy"
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/eytan-avisror/ttsum/pkg/webhook"
+	"github.com/spf13/cobra"
+)
+
+var (
+	webhookAddr     string
+	tlsCertFile     string
+	tlsKeyFile      string
+	policyFile      string
+	policyConfigMap string
+	auditOnly       bool
+	mutate          bool
+)
+
+var webhookCmd = &cobra.Command{
+	Use:   "webhook --policy <path> --tls-cert-file <path> --tls-private-key-file <path>",
+	Short: "webhook runs an admission webhook which enforces toleration policies",
+	Long:  "For example; $ ttsum webhook --policy policy.yaml --tls-cert-file tls.crt --tls-private-key-file tls.key --audit, or $ ttsum webhook --mutate --policy-configmap ttsum/policy ...",
+	Run:   RunWebhookCommand,
+}
+
+func RunWebhookCommand(cmd *cobra.Command, args []string) {
+	if (policyFile == "") == (policyConfigMap == "") {
+		log.Fatal("must provide one of --policy or --policy-configmap")
+	}
+	if tlsCertFile == "" || tlsKeyFile == "" {
+		log.Fatal("must provide --tls-cert-file and --tls-private-key-file")
+	}
+
+	k8s, err := getKubernetesClient(kubeconfigPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var p *policy.Policy
+	if policyFile != "" {
+		p, err = policy.Load(policyFile)
+	} else {
+		ref, parseErr := resources.ParseReference("configmap/" + policyConfigMap)
+		if parseErr != nil || ref.Namespace == "" {
+			log.Fatal("--policy-configmap must be in format namespace/name")
+		}
+		p, err = policy.LoadConfigMap(k8s, ref.Namespace, ref.Name)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/validate", &webhook.Validator{
+		Policy:     p,
+		Namespaces: webhook.NewNamespaceGetter(k8s),
+		AuditOnly:  auditOnly,
+	})
+	if mutate {
+		mux.Handle("/mutate", &webhook.Mutator{
+			Policy:     p,
+			Namespaces: webhook.NewNamespaceGetter(k8s),
+		})
+	}
+	mux.HandleFunc("/healthz", healthz)
+
+	srv := &http.Server{
+		Addr:              webhookAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("serving webhook on %v", webhookAddr)
+	log.Fatal(srv.ListenAndServeTLS(tlsCertFile, tlsKeyFile))
+}
+
+func init() {
+	rootCmd.AddCommand(webhookCmd)
+	webhookCmd.Flags().StringVar(&webhookAddr, "addr", ":8443", "Address to serve the webhook on")
+	webhookCmd.Flags().StringVar(&tlsCertFile, "tls-cert-file", "", "Path to the TLS certificate")
+	webhookCmd.Flags().StringVar(&tlsKeyFile, "tls-private-key-file", "", "Path to the TLS private key")
+	webhookCmd.Flags().StringVar(&policyFile, "policy", "", "Path to a toleration policy file")
+	webhookCmd.Flags().StringVar(&policyConfigMap, "policy-configmap", "", "ConfigMap holding the toleration policy under the policy.yaml key, in format namespace/name")
+	webhookCmd.Flags().BoolVar(&auditOnly, "audit", false, "Allow violating requests and return violations as warnings")
+	webhookCmd.Flags().BoolVar(&mutate, "mutate", false, "Serve a mutating webhook on /mutate which adds namespace default tolerations and enforces whitelists")
+}
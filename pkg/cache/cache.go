@@ -0,0 +1,253 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+// Cache serves node taints and workload tolerations from informers instead of listing on every call
+type Cache struct {
+	nodeFactory     dynamicinformer.DynamicSharedInformerFactory
+	workloadFactory dynamicinformer.DynamicSharedInformerFactory
+	workloads       []schema.GroupVersionResource
+	runtimeClasses  bool
+}
+
+// New creates informers for nodes, RuntimeClasses and the workload resources served by the cluster,
+// workloads are limited to namespace unless it is empty. RuntimeClasses are not watched when the
+// cluster does not serve them or they may not be listed
+func New(client dynamic.Interface, namespace string, resync time.Duration) (*Cache, error) {
+	c := &Cache{
+		nodeFactory:     dynamicinformer.NewDynamicSharedInformerFactory(client, resync),
+		workloadFactory: dynamicinformer.NewFilteredDynamicSharedInformerFactory(client, resync, namespace, nil),
+		workloads:       make([]schema.GroupVersionResource, 0),
+	}
+
+	c.nodeFactory.ForResource(resources.NodeGVR)
+	_, err := client.Resource(resources.RuntimeClassGVR).List(context.Background(), metav1.ListOptions{Limit: 1})
+	switch {
+	case err == nil:
+		c.nodeFactory.ForResource(resources.RuntimeClassGVR)
+		c.runtimeClasses = true
+	case !apierrors.IsNotFound(err) && !apierrors.IsForbidden(err):
+		return nil, errors.Wrapf(err, "failed to list %v", resources.RuntimeClassGVR.String())
+	}
+
+	for _, gvr := range resources.WorkloadGVRs {
+		_, err := client.Resource(gvr).Namespace(namespace).List(context.Background(), metav1.ListOptions{Limit: 1})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list %v", gvr.String())
+		}
+		c.workloadFactory.ForResource(gvr)
+		c.workloads = append(c.workloads, gvr)
+	}
+	return c, nil
+}
+
+// Start runs the informers until stopCh is closed and waits for the initial sync
+func (c *Cache) Start(stopCh <-chan struct{}) error {
+	c.nodeFactory.Start(stopCh)
+	c.workloadFactory.Start(stopCh)
+
+	for gvr, synced := range c.nodeFactory.WaitForCacheSync(stopCh) {
+		if !synced {
+			return errors.Errorf("failed to sync %v", gvr.String())
+		}
+	}
+	for gvr, synced := range c.workloadFactory.WaitForCacheSync(stopCh) {
+		if !synced {
+			return errors.Errorf("failed to sync %v", gvr.String())
+		}
+	}
+	return nil
+}
+
+// AddEventHandler calls fn whenever a node, RuntimeClass or workload is added, updated or deleted
+func (c *Cache) AddEventHandler(fn func()) {
+	handler := toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { fn() },
+		UpdateFunc: func(oldObj, newObj interface{}) { fn() },
+		DeleteFunc: func(obj interface{}) { fn() },
+	}
+
+	c.nodeFactory.ForResource(resources.NodeGVR).Informer().AddEventHandler(handler)
+	if c.runtimeClasses {
+		c.nodeFactory.ForResource(resources.RuntimeClassGVR).Informer().AddEventHandler(handler)
+	}
+	for _, gvr := range c.workloads {
+		c.workloadFactory.ForResource(gvr).Informer().AddEventHandler(handler)
+	}
+}
+
+// Workloads returns the workload resources watched by the cache
+func (c *Cache) Workloads() []schema.GroupVersionResource {
+	return c.workloads
+}
+
+// NodeTaints is the cached equivalent of resources.ListNodeTaints
+func (c *Cache) NodeTaints() (map[resources.ResourceReference][]v1.Taint, error) {
+	var taints = make(map[resources.ResourceReference][]v1.Taint)
+
+	objs, err := c.nodeFactory.ForResource(resources.NodeGVR).Lister().List(labels.Everything())
+	if err != nil {
+		return taints, err
+	}
+
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		taints[resources.ReferenceFor(u)], err = resources.TaintsFromObject(u)
+		if err != nil {
+			return taints, err
+		}
+	}
+	return taints, nil
+}
+
+// ResourceTolerations is the cached equivalent of resources.ListResourceTolerations
+func (c *Cache) ResourceTolerations(gvr schema.GroupVersionResource, namespace string) (map[resources.ResourceReference][]v1.Toleration, error) {
+	tolerations, specs, err := c.resourceTemplates(gvr, namespace)
+	if err != nil {
+		return tolerations, err
+	}
+
+	runtimeClasses, err := c.runtimeClassTolerations()
+	if err != nil {
+		return tolerations, err
+	}
+	return resources.MergeRuntimeClassTolerations(tolerations, specs, runtimeClasses), nil
+}
+
+// WorkloadTolerations is the cached equivalent of resources.ListWorkloadTolerations
+func (c *Cache) WorkloadTolerations(namespace string) (map[resources.ResourceReference][]v1.Toleration, error) {
+	var (
+		tolerations = make(map[resources.ResourceReference][]v1.Toleration)
+		specs       = make(map[resources.ResourceReference]v1.PodSpec)
+	)
+
+	for _, gvr := range c.workloads {
+		resourceTolerations, resourceSpecs, err := c.resourceTemplates(gvr, namespace)
+		if err != nil {
+			return tolerations, err
+		}
+		for ref, tols := range resourceTolerations {
+			tolerations[ref] = tols
+		}
+		for ref, spec := range resourceSpecs {
+			specs[ref] = spec
+		}
+	}
+
+	runtimeClasses, err := c.runtimeClassTolerations()
+	if err != nil {
+		return tolerations, err
+	}
+	return resources.MergeRuntimeClassTolerations(tolerations, specs, runtimeClasses), nil
+}
+
+// resourceTemplates is the cached equivalent of resources.ListResourceTemplates
+func (c *Cache) resourceTemplates(gvr schema.GroupVersionResource, namespace string) (map[resources.ResourceReference][]v1.Toleration, map[resources.ResourceReference]v1.PodSpec, error) {
+	var (
+		tolerations = make(map[resources.ResourceReference][]v1.Toleration)
+		specs       = make(map[resources.ResourceReference]v1.PodSpec)
+	)
+
+	if !c.watches(gvr) {
+		return tolerations, specs, errors.Errorf("resource %v is not cached", gvr.String())
+	}
+
+	var (
+		lister = c.workloadFactory.ForResource(gvr).Lister()
+		objs   []runtime.Object
+		err    error
+	)
+	if namespace == "" {
+		objs, err = lister.List(labels.Everything())
+	} else {
+		objs, err = lister.ByNamespace(namespace).List(labels.Everything())
+	}
+	if err != nil {
+		return tolerations, specs, err
+	}
+
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		ref := resources.ReferenceFor(u)
+		tolerations[ref], err = resources.TolerationsFromObject(u, resources.TolerationPathFor(gvr))
+		if err != nil {
+			return tolerations, specs, err
+		}
+		if spec, err := resources.PodSpecFromObject(u, resources.PodSpecPathFor(gvr)); err == nil {
+			specs[ref] = spec
+		}
+	}
+	return tolerations, specs, nil
+}
+
+// runtimeClassTolerations is the cached equivalent of resources.ListRuntimeClassTolerations
+func (c *Cache) runtimeClassTolerations() (map[string][]v1.Toleration, error) {
+	var runtimeClasses = make(map[string][]v1.Toleration)
+
+	if !c.runtimeClasses {
+		return runtimeClasses, nil
+	}
+
+	objs, err := c.nodeFactory.ForResource(resources.RuntimeClassGVR).Lister().List(labels.Everything())
+	if err != nil {
+		return runtimeClasses, err
+	}
+
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		runtimeClasses[u.GetName()], err = resources.TolerationsFromObject(u, resources.RuntimeClassTolerationPath)
+		if err != nil {
+			return runtimeClasses, err
+		}
+	}
+	return runtimeClasses, nil
+}
+
+func (c *Cache) watches(gvr schema.GroupVersionResource) bool {
+	for _, w := range c.workloads {
+		if w == gvr {
+			return true
+		}
+	}
+	return false
+}
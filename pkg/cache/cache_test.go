@@ -0,0 +1,165 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestCache(t *testing.T) {
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		resources.NodeGVR:         "NodeList",
+		resources.RuntimeClassGVR: "RuntimeClassList",
+		resources.DeploymentGVR:   "DeploymentList",
+		resources.StatefulSetGVR:  "StatefulSetList",
+		resources.DaemonSetGVR:    "DaemonSetList",
+		resources.JobGVR:          "JobList",
+		resources.CronJobGVR:      "CronJobList",
+	},
+		_unstructuredNode("node-a", v1.Taint{Key: "app", Value: "web", Effect: v1.TaintEffectNoSchedule}),
+		_unstructuredCronJob("default", "backup", v1.Toleration{Operator: v1.TolerationOpExists, Key: "app"}),
+	)
+
+	c, err := New(client, "", time.Minute)
+	assert.NoError(t, err)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	assert.NoError(t, c.Start(stopCh))
+
+	taints, err := c.NodeTaints()
+	assert.NoError(t, err)
+	assert.Equal(t, map[resources.ResourceReference][]v1.Taint{
+		{Name: "node-a", Kind: "Node"}: {{Key: "app", Value: "web", Effect: v1.TaintEffectNoSchedule}},
+	}, taints)
+
+	tolerations, err := c.WorkloadTolerations("default")
+	assert.NoError(t, err)
+	assert.Equal(t, map[resources.ResourceReference][]v1.Toleration{
+		{Namespace: "default", Name: "backup", Kind: "CronJob"}: {{Operator: v1.TolerationOpExists, Key: "app"}},
+	}, tolerations)
+}
+
+func TestCacheRuntimeClass(t *testing.T) {
+	sandboxed := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"namespace": "default", "name": "sandboxed"},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"runtimeClassName": "gvisor",
+						"containers":       []interface{}{map[string]interface{}{"name": "app", "image": "app"}},
+						"tolerations":      []interface{}{map[string]interface{}{"key": "app", "operator": "Exists"}},
+					},
+				},
+			},
+		},
+	}
+	gvisor := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "node.k8s.io/v1",
+			"kind":       "RuntimeClass",
+			"metadata":   map[string]interface{}{"name": "gvisor"},
+			"handler":    "runsc",
+			"scheduling": map[string]interface{}{
+				"tolerations": []interface{}{map[string]interface{}{"key": "sandbox", "operator": "Equal", "value": "gvisor", "effect": "NoSchedule"}},
+			},
+		},
+	}
+
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		resources.NodeGVR:         "NodeList",
+		resources.RuntimeClassGVR: "RuntimeClassList",
+		resources.DeploymentGVR:   "DeploymentList",
+		resources.StatefulSetGVR:  "StatefulSetList",
+		resources.DaemonSetGVR:    "DaemonSetList",
+		resources.JobGVR:          "JobList",
+		resources.CronJobGVR:      "CronJobList",
+	}, sandboxed, gvisor)
+
+	c, err := New(client, "", time.Minute)
+	assert.NoError(t, err)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	assert.NoError(t, c.Start(stopCh))
+
+	expected := map[resources.ResourceReference][]v1.Toleration{
+		{Namespace: "default", Name: "sandboxed", Kind: "Deployment"}: {
+			{Key: "app", Operator: v1.TolerationOpExists},
+			{Key: "sandbox", Operator: v1.TolerationOpEqual, Value: "gvisor", Effect: v1.TaintEffectNoSchedule},
+		},
+	}
+
+	tolerations, err := c.ResourceTolerations(resources.DeploymentGVR, "")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, tolerations)
+
+	tolerations, err = c.WorkloadTolerations("")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, tolerations)
+}
+
+func _unstructuredNode(name string, taints ...v1.Taint) *unstructured.Unstructured {
+	base := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Node",
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+		},
+	}
+
+	unstructuredTaints := make([]interface{}, 0)
+	for _, t := range taints {
+		ts, _ := runtime.DefaultUnstructuredConverter.ToUnstructured(&t)
+		unstructuredTaints = append(unstructuredTaints, ts)
+	}
+
+	unstructured.SetNestedField(base.Object, unstructuredTaints, resources.TaintPath...)
+	return base
+}
+
+func _unstructuredCronJob(namespace, name string, tolerations ...v1.Toleration) *unstructured.Unstructured {
+	base := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       "CronJob",
+			"metadata": map[string]interface{}{
+				"namespace": namespace,
+				"name":      name,
+			},
+		},
+	}
+
+	unstructuredTolerations := make([]interface{}, 0)
+	for _, t := range tolerations {
+		ts, _ := runtime.DefaultUnstructuredConverter.ToUnstructured(&t)
+		unstructuredTolerations = append(unstructuredTolerations, ts)
+	}
+
+	unstructured.SetNestedField(base.Object, unstructuredTolerations, resources.TolerationPathFor(resources.CronJobGVR)...)
+	return base
+}
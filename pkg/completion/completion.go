@@ -0,0 +1,147 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package completion
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+// APIVersions returns the group versions serving namespaced resources which can be listed
+func APIVersions(lists []*metav1.APIResourceList) []string {
+	versions := make([]string, 0, len(lists))
+	for _, list := range lists {
+		if len(listable(list)) > 0 {
+			versions = append(versions, list.GroupVersion)
+		}
+	}
+	return unique(versions)
+}
+
+// Resources returns the namespaced resources of a group version which can be listed
+func Resources(lists []*metav1.APIResourceList, apiVersion string) []string {
+	names := make([]string, 0)
+	for _, list := range lists {
+		if list.GroupVersion == apiVersion {
+			names = append(names, listable(list)...)
+		}
+	}
+	return unique(names)
+}
+
+func listable(list *metav1.APIResourceList) []string {
+	names := make([]string, 0)
+	if list == nil {
+		return names
+	}
+	for _, r := range list.APIResources {
+		// subresources such as deployments/scale cannot be listed
+		if !r.Namespaced || strings.Contains(r.Name, "/") {
+			continue
+		}
+		for _, verb := range r.Verbs {
+			if verb == "list" {
+				names = append(names, r.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// Namespaces returns the names of all namespaces
+func Namespaces(client dynamic.Interface) ([]string, error) {
+	r, err := client.Resource(resources.NamespaceGVR).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(r.Items))
+	for _, ns := range r.Items {
+		names = append(names, ns.GetName())
+	}
+	return unique(names), nil
+}
+
+// TaintExpressions returns the taints present on nodes in the format taints.Parse accepts, as
+// key, key=value, key:effect and key=value:effect
+func TaintExpressions(nodeTaints map[resources.ResourceReference][]v1.Taint) []string {
+	exprs := make([]string, 0)
+	for _, taints := range nodeTaints {
+		for _, t := range taints {
+			exprs = append(exprs, t.Key)
+			if t.Value != "" {
+				exprs = append(exprs, t.Key+"="+t.Value)
+			}
+			if t.Effect != "" {
+				exprs = append(exprs, t.Key+":"+string(t.Effect))
+				if t.Value != "" {
+					exprs = append(exprs, t.Key+"="+t.Value+":"+string(t.Effect))
+				}
+			}
+		}
+	}
+	return unique(exprs)
+}
+
+// TolerationExpressions returns tolerations of the taints present on nodes in the format
+// tolerations.Parse accepts, as Exists(key), Exists(key:effect), Equal(key=value) and
+// Equal(key=value:effect)
+func TolerationExpressions(nodeTaints map[resources.ResourceReference][]v1.Taint) []string {
+	exprs := make([]string, 0)
+	for _, taints := range nodeTaints {
+		for _, t := range taints {
+			exprs = append(exprs, "Exists("+t.Key+")")
+			if t.Effect != "" {
+				exprs = append(exprs, "Exists("+t.Key+":"+string(t.Effect)+")")
+			}
+			if t.Value != "" {
+				exprs = append(exprs, "Equal("+t.Key+"="+t.Value+")")
+				if t.Effect != "" {
+					exprs = append(exprs, "Equal("+t.Key+"="+t.Value+":"+string(t.Effect)+")")
+				}
+			}
+		}
+	}
+	return unique(exprs)
+}
+
+// Filter returns the candidates starting with prefix
+func Filter(candidates []string, prefix string) []string {
+	filtered := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+func unique(values []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
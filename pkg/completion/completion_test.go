@@ -0,0 +1,109 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package completion
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/eytan-avisror/ttsum/pkg/taints"
+	"github.com/eytan-avisror/ttsum/pkg/tolerations"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestResources(t *testing.T) {
+	lists := []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", Namespaced: true, Verbs: metav1.Verbs{"get", "list"}},
+				{Name: "pods/log", Namespaced: true, Verbs: metav1.Verbs{"get"}},
+				{Name: "nodes", Namespaced: false, Verbs: metav1.Verbs{"get", "list"}},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Namespaced: true, Verbs: metav1.Verbs{"get", "list"}},
+				{Name: "deployments/scale", Namespaced: true, Verbs: metav1.Verbs{"get", "list"}},
+				{Name: "daemonsets", Namespaced: true, Verbs: metav1.Verbs{"get", "list"}},
+			},
+		},
+		{
+			GroupVersion: "authentication.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "tokenreviews", Verbs: metav1.Verbs{"create"}},
+			},
+		},
+	}
+
+	assert.Equal(t, []string{"apps/v1", "v1"}, APIVersions(lists))
+	assert.Equal(t, []string{"daemonsets", "deployments"}, Resources(lists, "apps/v1"))
+	assert.Equal(t, []string{"pods"}, Resources(lists, "v1"))
+	assert.Equal(t, []string{"deployments"}, Filter(Resources(lists, "apps/v1"), "dep"))
+}
+
+func TestExpressions(t *testing.T) {
+	nodeTaints := map[resources.ResourceReference][]v1.Taint{
+		{Name: "db-1", Kind: "Node"}:  {{Key: "app", Value: "db", Effect: v1.TaintEffectNoSchedule}},
+		{Name: "db-2", Kind: "Node"}:  {{Key: "app", Value: "db", Effect: v1.TaintEffectNoSchedule}},
+		{Name: "gpu-1", Kind: "Node"}: {{Key: "nvidia.com/gpu", Effect: v1.TaintEffectNoSchedule}},
+	}
+
+	taintExprs := TaintExpressions(nodeTaints)
+	assert.Equal(t, []string{"app", "app:NoSchedule", "app=db", "app=db:NoSchedule", "nvidia.com/gpu", "nvidia.com/gpu:NoSchedule"}, taintExprs)
+	for _, expr := range taintExprs {
+		_, err := taints.Parse(expr)
+		assert.NoError(t, err, expr)
+	}
+
+	tolerationExprs := TolerationExpressions(nodeTaints)
+	assert.Equal(t, []string{
+		"Equal(app=db)",
+		"Equal(app=db:NoSchedule)",
+		"Exists(app)",
+		"Exists(app:NoSchedule)",
+		"Exists(nvidia.com/gpu)",
+		"Exists(nvidia.com/gpu:NoSchedule)",
+	}, tolerationExprs)
+	for _, expr := range tolerationExprs {
+		_, err := tolerations.Parse(expr)
+		assert.NoError(t, err, expr)
+	}
+}
+
+func TestNamespaces(t *testing.T) {
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		resources.NamespaceGVR: "NamespaceList",
+	})
+	for _, name := range []string{"kube-system", "default"} {
+		ns := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata":   map[string]interface{}{"name": name},
+		}}
+		_, err := client.Resource(resources.NamespaceGVR).Create(context.Background(), ns, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	namespaces, err := Namespaces(client)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"default", "kube-system"}, namespaces)
+}
@@ -0,0 +1,230 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// EnvConfig overrides the path of the config file
+	EnvConfig = "TTSUM_CONFIG"
+	// EnvOutput overrides the default output format
+	EnvOutput = "TTSUM_OUTPUT"
+	// EnvNamespace overrides the default namespace
+	EnvNamespace = "TTSUM_NAMESPACE"
+	// EnvSystemNamespaces overrides the lint system namespaces, comma separated
+	EnvSystemNamespaces = "TTSUM_SYSTEM_NAMESPACES"
+	// EnvPolicy overrides the path of the toleration policy file
+	EnvPolicy = "TTSUM_POLICY"
+	// EnvPolicyConfigMap overrides the ConfigMap holding the toleration policy
+	EnvPolicyConfigMap = "TTSUM_POLICY_CONFIGMAP"
+	// EnvAudit overrides whether policies are only audited
+	EnvAudit = "TTSUM_AUDIT"
+)
+
+// Config holds defaults for command flags, extra toleration paths and named query aliases
+type Config struct {
+	// Output is the default output format of commands printing tables
+	Output string `json:"output,omitempty"`
+	// Namespace is the default namespace
+	Namespace string `json:"namespace,omitempty"`
+	// TolerationPaths registers the toleration paths of custom resources
+	TolerationPaths []TolerationPath `json:"tolerationPaths,omitempty"`
+	// Aliases are named queries, expanded into the arguments of a command, e.g.
+	// gpu-workloads: tolerations apps/v1 deployments --match Exists(nvidia.com/gpu)
+	Aliases map[string]string `json:"aliases,omitempty"`
+	Lint    Lint              `json:"lint,omitempty"`
+	Policy  Policy            `json:"policy,omitempty"`
+}
+
+// TolerationPath is the path to the pod tolerations of a resource, Workload resources are
+// included when tolerations are listed for all workloads
+type TolerationPath struct {
+	APIVersion string   `json:"apiVersion"`
+	Resource   string   `json:"resource"`
+	Path       []string `json:"path"`
+	Workload   bool     `json:"workload,omitempty"`
+}
+
+// Lint holds defaults of the lint commands
+type Lint struct {
+	SystemNamespaces []string `json:"systemNamespaces,omitempty"`
+}
+
+// Policy holds defaults of the toleration policy enforced or audited by the webhook
+type Policy struct {
+	File      string `json:"file,omitempty"`
+	ConfigMap string `json:"configMap,omitempty"`
+	Audit     bool   `json:"audit,omitempty"`
+}
+
+// DefaultPath returns the path of the config file, $TTSUM_CONFIG or config.yaml in the ttsum
+// directory of $XDG_CONFIG_HOME, which defaults to ~/.config
+func DefaultPath() string {
+	if path := os.Getenv(EnvConfig); path != "" {
+		return path
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "ttsum", "config.yaml")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "ttsum", "config.yaml")
+}
+
+// Load reads a config file, a missing file is an empty config
+func Load(path string) (*Config, error) {
+	config := &Config{}
+	if path == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read config %v", path)
+	}
+
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse config %v", path)
+	}
+	return config, config.Validate()
+}
+
+// Validate returns an error if a toleration path or alias is invalid
+func (c *Config) Validate() error {
+	for _, tp := range c.TolerationPaths {
+		if tp.APIVersion == "" || tp.Resource == "" || len(tp.Path) == 0 {
+			return errors.Errorf("toleration path of %v %v must have apiVersion, resource and path", tp.APIVersion, tp.Resource)
+		}
+	}
+	for name, alias := range c.Aliases {
+		args, err := SplitArgs(alias)
+		if err != nil {
+			return errors.Wrapf(err, "invalid alias %v", name)
+		}
+		if len(args) == 0 {
+			return errors.Errorf("alias %v is empty", name)
+		}
+	}
+	return nil
+}
+
+// ApplyEnv overrides the config with TTSUM_* environment variables found by lookup
+func (c *Config) ApplyEnv(lookup func(string) (string, bool)) error {
+	if v, ok := lookup(EnvOutput); ok {
+		c.Output = v
+	}
+	if v, ok := lookup(EnvNamespace); ok {
+		c.Namespace = v
+	}
+	if v, ok := lookup(EnvSystemNamespaces); ok {
+		c.Lint.SystemNamespaces = strings.Split(v, ",")
+	}
+	if v, ok := lookup(EnvPolicy); ok {
+		c.Policy.File = v
+	}
+	if v, ok := lookup(EnvPolicyConfigMap); ok {
+		c.Policy.ConfigMap = v
+	}
+	if v, ok := lookup(EnvAudit); ok {
+		audit, err := strconv.ParseBool(v)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %v", EnvAudit)
+		}
+		c.Policy.Audit = audit
+	}
+	return nil
+}
+
+// RegisterTolerationPaths adds the toleration paths to resources.TolerationPaths, and workload
+// resources to resources.WorkloadGVRs
+func (c *Config) RegisterTolerationPaths() {
+	for _, tp := range c.TolerationPaths {
+		gvr := resources.Parse(tp.APIVersion, tp.Resource)
+		resources.TolerationPaths[gvr] = tp.Path
+
+		registered := false
+		for _, w := range resources.WorkloadGVRs {
+			if w == gvr {
+				registered = true
+			}
+		}
+		if tp.Workload && !registered {
+			resources.WorkloadGVRs = append(resources.WorkloadGVRs, gvr)
+		}
+	}
+}
+
+// Expand replaces an alias in the first argument with its arguments
+func (c *Config) Expand(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+	alias, ok := c.Aliases[args[0]]
+	if !ok {
+		return args
+	}
+	expanded, _ := SplitArgs(alias)
+	return append(expanded, args[1:]...)
+}
+
+// SplitArgs splits a command line into arguments like a shell, arguments may be quoted with
+// single or double quotes
+func SplitArgs(s string) ([]string, error) {
+	var (
+		args    = make([]string, 0)
+		current strings.Builder
+		quote   rune
+		inArg   bool
+	)
+	for _, r := range s {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.Errorf("unterminated quote in %v", s)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,148 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		Description string
+		Content     string
+		Expected    *Config
+		ExpectError bool
+	}{
+		{
+			Description: "defaults, aliases and toleration paths",
+			Content: `
+output: markdown
+namespace: kube-system
+aliases:
+  gpu-workloads: tolerations --all-workloads --match "Exists(nvidia.com/gpu)"
+tolerationPaths:
+- apiVersion: example.com/v1
+  resource: widgets
+  path: [spec, pod, tolerations]
+  workload: true
+lint:
+  systemNamespaces: [kube-system]
+policy:
+  file: policy.yaml
+  audit: true
+`,
+			Expected: &Config{
+				Output:    "markdown",
+				Namespace: "kube-system",
+				Aliases:   map[string]string{"gpu-workloads": `tolerations --all-workloads --match "Exists(nvidia.com/gpu)"`},
+				TolerationPaths: []TolerationPath{
+					{APIVersion: "example.com/v1", Resource: "widgets", Path: []string{"spec", "pod", "tolerations"}, Workload: true},
+				},
+				Lint:   Lint{SystemNamespaces: []string{"kube-system"}},
+				Policy: Policy{File: "policy.yaml", Audit: true},
+			},
+		},
+		{
+			Description: "unknown field",
+			Content:     "outputs: markdown\n",
+			ExpectError: true,
+		},
+		{
+			Description: "toleration path without path",
+			Content:     "tolerationPaths:\n- apiVersion: example.com/v1\n  resource: widgets\n",
+			ExpectError: true,
+		},
+		{
+			Description: "alias with unterminated quote",
+			Content:     "aliases:\n  broken: taints --match \"app\n",
+			ExpectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		assert.NoError(t, os.WriteFile(path, []byte(test.Content), 0644))
+
+		config, err := Load(path)
+		if test.ExpectError {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, test.Expected, config)
+	}
+
+	config, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, &Config{}, config)
+}
+
+func TestApplyEnv(t *testing.T) {
+	env := map[string]string{
+		EnvOutput:           "csv",
+		EnvSystemNamespaces: "kube-system,monitoring",
+		EnvAudit:            "true",
+	}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	config := &Config{Output: "markdown", Namespace: "default"}
+	assert.NoError(t, config.ApplyEnv(lookup))
+	assert.Equal(t, &Config{
+		Output:    "csv",
+		Namespace: "default",
+		Lint:      Lint{SystemNamespaces: []string{"kube-system", "monitoring"}},
+		Policy:    Policy{Audit: true},
+	}, config)
+
+	env[EnvAudit] = "sometimes"
+	assert.Error(t, config.ApplyEnv(lookup))
+}
+
+func TestExpand(t *testing.T) {
+	config := &Config{Aliases: map[string]string{
+		"gpu-workloads": `tolerations --all-workloads --match 'Exists(nvidia.com/gpu)'`,
+	}}
+
+	assert.Equal(t, []string{"tolerations", "--all-workloads", "--match", "Exists(nvidia.com/gpu)", "-n", "ml"},
+		config.Expand([]string{"gpu-workloads", "-n", "ml"}))
+	assert.Equal(t, []string{"taints"}, config.Expand([]string{"taints"}))
+	assert.Empty(t, config.Expand(nil))
+}
+
+func TestRegisterTolerationPaths(t *testing.T) {
+	workloads := resources.WorkloadGVRs
+	defer func() {
+		resources.WorkloadGVRs = workloads
+	}()
+
+	config := &Config{TolerationPaths: []TolerationPath{
+		{APIVersion: "example.com/v1", Resource: "widgets", Path: []string{"spec", "pod", "tolerations"}, Workload: true},
+	}}
+	config.RegisterTolerationPaths()
+	config.RegisterTolerationPaths()
+
+	gvr := resources.Parse("example.com/v1", "widgets")
+	defer delete(resources.TolerationPaths, gvr)
+	assert.Equal(t, []string{"spec", "pod", "tolerations"}, resources.TolerationPathFor(gvr))
+	assert.Equal(t, len(workloads)+1, len(resources.WorkloadGVRs))
+	assert.Equal(t, gvr, resources.WorkloadGVRs[len(resources.WorkloadGVRs)-1])
+}
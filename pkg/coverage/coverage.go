@@ -0,0 +1,167 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coverage
+
+import (
+	"context"
+	"sort"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+)
+
+// Uncovered are nodes a DaemonSet cannot run on because it does not tolerate Taints
+type Uncovered struct {
+	Taints []v1.Taint                    `json:"taints"`
+	Nodes  []resources.ResourceReference `json:"nodes"`
+}
+
+// Report is the node coverage of a DaemonSet
+type Report struct {
+	resources.ResourceReference
+	Nodes int `json:"nodes"`
+	// Eligible are the nodes the DaemonSet can run on
+	Eligible []resources.ResourceReference `json:"eligible"`
+	// Excluded are the nodes which do not match the DaemonSet nodeSelector or required node affinity
+	Excluded []resources.ResourceReference `json:"excluded"`
+	// Uncovered are the nodes matching the DaemonSet selectors which have untolerated taints,
+	// grouped by the untolerated taints
+	Uncovered              []Uncovered `json:"uncovered"`
+	DesiredNumberScheduled int32       `json:"desiredNumberScheduled"`
+	CurrentNumberScheduled int32       `json:"currentNumberScheduled"`
+	Pods                   int         `json:"pods"`
+	// Mismatch is true when the eligible node count differs from DesiredNumberScheduled,
+	// CurrentNumberScheduled or Pods
+	Mismatch bool `json:"mismatch"`
+}
+
+// ListDaemonSets lists DaemonSets
+func ListDaemonSets(client dynamic.Interface, namespace string) ([]appsv1.DaemonSet, error) {
+	daemonSets := make([]appsv1.DaemonSet, 0)
+
+	r, err := client.Resource(resources.DaemonSetGVR).Namespace(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return daemonSets, err
+	}
+
+	for i := range r.Items {
+		var ds appsv1.DaemonSet
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(r.Items[i].Object, &ds); err != nil {
+			return daemonSets, err
+		}
+		daemonSets = append(daemonSets, ds)
+	}
+	return daemonSets, nil
+}
+
+// PodSpecs returns the pod template spec of each DaemonSet, for resources.ListEffectiveOptions
+func PodSpecs(daemonSets []appsv1.DaemonSet) map[resources.ResourceReference]v1.PodSpec {
+	specs := make(map[resources.ResourceReference]v1.PodSpec, len(daemonSets))
+	for _, ds := range daemonSets {
+		specs[resources.ResourceReference{Namespace: ds.Namespace, Name: ds.Name, Kind: "DaemonSet"}] = ds.Spec.Template.Spec
+	}
+	return specs
+}
+
+// CountPods counts the pods owned by each DaemonSet
+func CountPods(client dynamic.Interface, namespace string) (map[resources.ResourceReference]int, error) {
+	counts := make(map[resources.ResourceReference]int)
+
+	r, err := client.Resource(resources.PodGVR).Namespace(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return counts, err
+	}
+
+	for _, pod := range r.Items {
+		for _, owner := range pod.GetOwnerReferences() {
+			if owner.Kind == "DaemonSet" {
+				counts[resources.ResourceReference{Namespace: pod.GetNamespace(), Name: owner.Name, Kind: owner.Kind}]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+// Compute returns the coverage of a DaemonSet, its tolerations include those the DaemonSet controller
+// adds along with the namespace and RuntimeClass tolerations of opts, see resources.ListEffectiveOptions
+func Compute(ds appsv1.DaemonSet, nodes map[resources.ResourceReference]v1.Node, pods int, opts resources.EffectiveOptions) Report {
+	ref := resources.ResourceReference{Namespace: ds.Namespace, Name: ds.Name, Kind: "DaemonSet"}
+	report := Report{
+		ResourceReference:      ref,
+		Nodes:                  len(nodes),
+		Eligible:               make([]resources.ResourceReference, 0),
+		Excluded:               make([]resources.ResourceReference, 0),
+		Uncovered:              make([]Uncovered, 0),
+		DesiredNumberScheduled: ds.Status.DesiredNumberScheduled,
+		CurrentNumberScheduled: ds.Status.CurrentNumberScheduled,
+		Pods:                   pods,
+	}
+
+	spec := ds.Spec.Template.Spec
+	opts.Implicit = true
+	opts.PodSpecs = map[resources.ResourceReference]v1.PodSpec{ref: spec}
+	effective := resources.EffectiveTolerations(map[resources.ResourceReference][]v1.Toleration{ref: spec.Tolerations}, opts)
+	tolerations := resources.PlainTolerations(effective[ref].Tolerations)
+	affinity := nodeaffinity.GetRequiredNodeAffinity(&v1.Pod{Spec: spec})
+
+	uncovered := make(map[string]*Uncovered)
+	for nodeRef, node := range nodes {
+		node := node
+		if match, err := affinity.Match(&node); err != nil || !match {
+			report.Excluded = append(report.Excluded, nodeRef)
+			continue
+		}
+
+		untolerated := resources.UntoleratedTaints(tolerations, node.Spec.Taints)
+		if len(untolerated) == 0 {
+			report.Eligible = append(report.Eligible, nodeRef)
+			continue
+		}
+
+		key := resources.TaintSetKey(untolerated)
+		if _, ok := uncovered[key]; !ok {
+			uncovered[key] = &Uncovered{Taints: untolerated}
+		}
+		uncovered[key].Nodes = append(uncovered[key].Nodes, nodeRef)
+	}
+
+	keys := make([]string, 0, len(uncovered))
+	for key := range uncovered {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		resources.SortReferences(uncovered[key].Nodes)
+		report.Uncovered = append(report.Uncovered, *uncovered[key])
+	}
+	resources.SortReferences(report.Eligible)
+	resources.SortReferences(report.Excluded)
+
+	eligible := len(report.Eligible)
+	report.Mismatch = eligible != int(report.DesiredNumberScheduled) || eligible != int(report.CurrentNumberScheduled) || eligible != report.Pods
+	return report
+}
+
+// UncoveredNodes returns the number of nodes matching the DaemonSet selectors which it cannot run on
+func (r Report) UncoveredNodes() int {
+	count := 0
+	for _, u := range r.Uncovered {
+		count += len(u.Nodes)
+	}
+	return count
+}
@@ -0,0 +1,127 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coverage
+
+import (
+	"testing"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCompute(t *testing.T) {
+	nodes := map[resources.ResourceReference]v1.Node{}
+	for _, node := range []v1.Node{
+		_node("web-1", map[string]string{"os": "linux"}),
+		_node("cordoned", map[string]string{"os": "linux"}, v1.Taint{Key: v1.TaintNodeUnschedulable, Effect: v1.TaintEffectNoSchedule}),
+		_node("db-1", map[string]string{"os": "linux"}, v1.Taint{Key: "app", Value: "db", Effect: v1.TaintEffectNoSchedule}),
+		_node("db-2", map[string]string{"os": "linux"}, v1.Taint{Key: "app", Value: "db", Effect: v1.TaintEffectNoSchedule}),
+		_node("gpu-1", map[string]string{"os": "linux"}, v1.Taint{Key: "nvidia.com/gpu", Effect: v1.TaintEffectNoSchedule}),
+		_node("win-1", map[string]string{"os": "windows"}),
+	} {
+		nodes[resources.ResourceReference{Name: node.Name, Kind: "Node"}] = node
+	}
+
+	ds := appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "logging", Name: "fluent-bit"},
+		Spec: appsv1.DaemonSetSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					NodeSelector: map[string]string{"os": "linux"},
+					Tolerations:  []v1.Toleration{{Key: "nvidia.com/gpu", Operator: v1.TolerationOpExists}},
+				},
+			},
+		},
+		Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, CurrentNumberScheduled: 3},
+	}
+
+	report := Compute(ds, nodes, 3, resources.EffectiveOptions{})
+	assert.Equal(t, 6, report.Nodes)
+	assert.Equal(t, []resources.ResourceReference{
+		{Name: "cordoned", Kind: "Node"},
+		{Name: "gpu-1", Kind: "Node"},
+		{Name: "web-1", Kind: "Node"},
+	}, report.Eligible)
+	assert.Equal(t, []resources.ResourceReference{{Name: "win-1", Kind: "Node"}}, report.Excluded)
+	assert.Equal(t, []Uncovered{
+		{
+			Taints: []v1.Taint{{Key: "app", Value: "db", Effect: v1.TaintEffectNoSchedule}},
+			Nodes:  []resources.ResourceReference{{Name: "db-1", Kind: "Node"}, {Name: "db-2", Kind: "Node"}},
+		},
+	}, report.Uncovered)
+	assert.Equal(t, 2, report.UncoveredNodes())
+	assert.Equal(t, 3, report.Pods)
+	assert.False(t, report.Mismatch)
+}
+
+func TestComputeRuntimeClassAndMismatch(t *testing.T) {
+	sandbox := v1.Taint{Key: "sandbox", Value: "gvisor", Effect: v1.TaintEffectNoSchedule}
+	nodes := map[resources.ResourceReference]v1.Node{}
+	for _, node := range []v1.Node{
+		_node("web-1", nil),
+		_node("sandbox-1", nil, sandbox),
+		_node("db-1", nil, v1.Taint{Key: "app", Value: "db", Effect: v1.TaintEffectNoSchedule}),
+	} {
+		nodes[resources.ResourceReference{Name: node.Name, Kind: "Node"}] = node
+	}
+
+	gvisor := "gvisor"
+	ds := appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "logging", Name: "fluent-bit"},
+		Spec: appsv1.DaemonSetSpec{
+			Template: v1.PodTemplateSpec{Spec: v1.PodSpec{RuntimeClassName: &gvisor}},
+		},
+		Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, CurrentNumberScheduled: 2},
+	}
+	opts := resources.EffectiveOptions{
+		RuntimeClasses: map[string][]v1.Toleration{gvisor: {{Key: "sandbox", Operator: v1.TolerationOpExists}}},
+	}
+
+	tests := []struct {
+		Description      string
+		Pods             int
+		ExpectedMismatch bool
+	}{
+		{
+			Description: "every eligible node runs a pod",
+			Pods:        2,
+		},
+		{
+			Description:      "pods are missing",
+			Pods:             1,
+			ExpectedMismatch: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		report := Compute(ds, nodes, test.Pods, opts)
+		assert.Equal(t, []resources.ResourceReference{{Name: "sandbox-1", Kind: "Node"}, {Name: "web-1", Kind: "Node"}}, report.Eligible)
+		assert.Equal(t, 1, report.UncoveredNodes())
+		assert.Equal(t, test.ExpectedMismatch, report.Mismatch)
+	}
+
+	report := Compute(ds, nodes, 2, resources.EffectiveOptions{})
+	assert.Equal(t, 2, report.UncoveredNodes())
+	assert.True(t, report.Mismatch)
+}
+
+func _node(name string, labels map[string]string, taints ...v1.Taint) v1.Node {
+	return v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec:       v1.NodeSpec{Taints: taints},
+	}
+}
@@ -0,0 +1,270 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describe
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/eytan-avisror/ttsum/pkg/taints"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	// PlacementLabels are the well-known node labels workloads commonly select or spread on
+	PlacementLabels = []string{
+		v1.LabelHostname,
+		v1.LabelTopologyZone,
+		v1.LabelTopologyRegion,
+		v1.LabelInstanceTypeStable,
+		v1.LabelOSStable,
+		v1.LabelArchStable,
+		"karpenter.sh/nodepool",
+		"karpenter.sh/capacity-type",
+		"eks.amazonaws.com/nodegroup",
+		"eks.amazonaws.com/capacityType",
+		"cloud.google.com/gke-nodepool",
+		"kubernetes.azure.com/agentpool",
+	}
+
+	// PlacementLabelPrefixes are prefixes of node labels relevant to placement
+	PlacementLabelPrefixes = []string{
+		"node-role.kubernetes.io/",
+	}
+)
+
+// Taint is a node taint along with its catalog class and the workloads which tolerate it
+type Taint struct {
+	v1.Taint
+	Class      taints.Class                  `json:"class"`
+	Tolerating []resources.ResourceReference `json:"tolerating"`
+}
+
+// Pod is a pod running on a node
+type Pod struct {
+	resources.ResourceReference
+	Owner string      `json:"owner,omitempty"`
+	Phase v1.PodPhase `json:"phase"`
+}
+
+// NodeDescription is the detail view of a node
+type NodeDescription struct {
+	Name          string            `json:"name"`
+	Unschedulable bool              `json:"unschedulable"`
+	Labels        map[string]string `json:"labels"`
+	Taints        []Taint           `json:"taints"`
+	Pods          []Pod             `json:"pods"`
+}
+
+// Toleration is an effective toleration along with the nodes it unlocks
+type Toleration struct {
+	resources.EffectiveToleration
+	Unlocks []resources.ResourceReference `json:"unlocks"`
+}
+
+// WorkloadDescription is the detail view of a workload
+type WorkloadDescription struct {
+	resources.ResourceReference
+	Tolerations []Toleration                  `json:"tolerations"`
+	Rejected    []v1.Toleration               `json:"rejected,omitempty"`
+	Eligible    []resources.ResourceReference `json:"eligible"`
+	Nodes       int                           `json:"nodes"`
+}
+
+// Node describes a node, its taints are tolerated by the workloads of workloadTolerations which
+// should hold effective tolerations, see ListEffectiveTolerations
+func Node(node v1.Node, workloadTolerations map[resources.ResourceReference][]v1.Toleration, pods []v1.Pod) NodeDescription {
+	description := NodeDescription{
+		Name:          node.Name,
+		Unschedulable: node.Spec.Unschedulable,
+		Labels:        PlacementLabelsOf(node.Labels),
+		Taints:        make([]Taint, 0, len(node.Spec.Taints)),
+		Pods:          make([]Pod, 0, len(pods)),
+	}
+
+	workloads := resources.TolerationsResults(workloadTolerations)
+	for i := range node.Spec.Taints {
+		t := Taint{
+			Taint:      node.Spec.Taints[i],
+			Class:      taints.Explain(node.Spec.Taints[i]).Class,
+			Tolerating: make([]resources.ResourceReference, 0),
+		}
+		for _, workload := range workloads {
+			if resources.ToleratesTaint(workload.Tolerations, &node.Spec.Taints[i]) {
+				t.Tolerating = append(t.Tolerating, workload.ResourceReference)
+			}
+		}
+		description.Taints = append(description.Taints, t)
+	}
+
+	for _, pod := range pods {
+		p := Pod{
+			ResourceReference: resources.ResourceReference{Namespace: pod.Namespace, Name: pod.Name, Kind: "Pod"},
+			Phase:             pod.Status.Phase,
+		}
+		if owner := metav1.GetControllerOf(&pod); owner != nil {
+			p.Owner = strings.ToLower(owner.Kind) + "/" + owner.Name
+		}
+		description.Pods = append(description.Pods, p)
+	}
+	sort.Slice(description.Pods, func(i, j int) bool {
+		return resources.LessReference(description.Pods[i].ResourceReference, description.Pods[j].ResourceReference)
+	})
+	return description
+}
+
+// PlacementLabelsOf returns the labels relevant to placement
+func PlacementLabelsOf(labels map[string]string) map[string]string {
+	placement := make(map[string]string)
+	for key, value := range labels {
+		if isPlacementLabel(key) {
+			placement[key] = value
+		}
+	}
+	return placement
+}
+
+func isPlacementLabel(key string) bool {
+	for _, label := range PlacementLabels {
+		if key == label {
+			return true
+		}
+	}
+	for _, prefix := range PlacementLabelPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Workload describes the effective tolerations of a workload, and the nodes each of them unlocks,
+// a node is unlocked by a toleration which tolerates any of its NoSchedule or NoExecute taints
+func Workload(effective resources.EffectiveTolerationsResult, nodeTaints map[resources.ResourceReference][]v1.Taint) WorkloadDescription {
+	plain := resources.PlainTolerations(effective.Tolerations)
+	description := WorkloadDescription{
+		ResourceReference: effective.ResourceReference,
+		Tolerations:       make([]Toleration, 0, len(effective.Tolerations)),
+		Rejected:          effective.Rejected,
+		Eligible:          resources.EligibleNodes(plain, nodeTaints),
+		Nodes:             len(nodeTaints),
+	}
+
+	nodes := resources.TaintsResults(nodeTaints)
+	for _, t := range effective.Tolerations {
+		toleration := Toleration{EffectiveToleration: t, Unlocks: make([]resources.ResourceReference, 0)}
+		for _, node := range nodes {
+			for i := range node.Taints {
+				if node.Taints[i].Effect != v1.TaintEffectPreferNoSchedule && t.ToleratesTaint(&node.Taints[i]) {
+					toleration.Unlocks = append(toleration.Unlocks, node.ResourceReference)
+					break
+				}
+			}
+		}
+		description.Tolerations = append(description.Tolerations, toleration)
+	}
+	return description
+}
+
+// ListNodePods lists the pods scheduled to a node
+func ListNodePods(client dynamic.Interface, nodeName string) ([]v1.Pod, error) {
+	pods := make([]v1.Pod, 0)
+
+	r, err := client.Resource(resources.PodGVR).List(context.Background(), metav1.ListOptions{
+		FieldSelector: "spec.nodeName=" + nodeName,
+	})
+	if err != nil {
+		return pods, err
+	}
+
+	for i := range r.Items {
+		var pod v1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(r.Items[i].Object, &pod); err != nil {
+			return pods, err
+		}
+		if pod.Spec.NodeName == nodeName {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+// WorkloadGVRFor returns the workload resource of a kind, e.g. deployment or Deployments
+func WorkloadGVRFor(kind string) (schema.GroupVersionResource, error) {
+	kind = strings.ToLower(kind)
+	for _, gvr := range append([]schema.GroupVersionResource{resources.PodGVR}, resources.WorkloadGVRs...) {
+		if gvr.Resource == kind || gvr.Resource == kind+"s" {
+			return gvr, nil
+		}
+	}
+	return schema.GroupVersionResource{}, errors.Errorf("unsupported workload kind %v", kind)
+}
+
+// EffectiveWorkloadTolerations gets a workload and returns its tolerations with the admission
+// behaviour of EffectiveTolerations overlaid
+func EffectiveWorkloadTolerations(client dynamic.Interface, ref resources.ResourceReference) (resources.EffectiveTolerationsResult, error) {
+	gvr, err := WorkloadGVRFor(ref.Kind)
+	if err != nil {
+		return resources.EffectiveTolerationsResult{}, err
+	}
+
+	obj, err := client.Resource(gvr).Namespace(ref.Namespace).Get(context.Background(), ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return resources.EffectiveTolerationsResult{}, err
+	}
+
+	tols, err := resources.TolerationsFromObject(obj, resources.TolerationPathFor(gvr))
+	if err != nil {
+		return resources.EffectiveTolerationsResult{}, err
+	}
+	spec, err := resources.PodSpecFromObject(obj, resources.PodSpecPathFor(gvr))
+	if err != nil {
+		return resources.EffectiveTolerationsResult{}, err
+	}
+
+	ref = resources.ReferenceFor(obj)
+	opts, err := resources.ListEffectiveOptions(client, map[resources.ResourceReference]v1.PodSpec{ref: spec})
+	if err != nil {
+		return resources.EffectiveTolerationsResult{}, err
+	}
+
+	effective := resources.EffectiveTolerations(map[resources.ResourceReference][]v1.Toleration{ref: tols}, opts)
+	return effective[ref], nil
+}
+
+// ListEffectiveTolerations lists the tolerations of all workloads with the admission behaviour of
+// EffectiveTolerations overlaid, like EffectiveWorkloadTolerations does for a single workload
+func ListEffectiveTolerations(client dynamic.Interface) (map[resources.ResourceReference][]v1.Toleration, error) {
+	tols, specs, err := resources.ListWorkloadTemplates(client, "")
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := resources.ListEffectiveOptions(client, specs)
+	if err != nil {
+		return nil, err
+	}
+
+	effective := make(map[resources.ResourceReference][]v1.Toleration)
+	for ref, result := range resources.EffectiveTolerations(tols, opts) {
+		effective[ref] = resources.PlainTolerations(result.Tolerations)
+	}
+	return effective, nil
+}
@@ -0,0 +1,205 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describe
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/eytan-avisror/ttsum/pkg/taints"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestNode(t *testing.T) {
+	node := v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "db-1",
+			Labels: map[string]string{
+				v1.LabelTopologyZone:                    "us-east-1a",
+				"node-role.kubernetes.io/db":            "",
+				"kubernetes.io/hostname":                "db-1",
+				"alpha.eksctl.io/cluster-name":          "prod",
+				"node.kubernetes.io/exclude-disruption": "true",
+			},
+		},
+		Spec: v1.NodeSpec{
+			Taints: []v1.Taint{
+				{Key: "app", Value: "db", Effect: v1.TaintEffectNoSchedule},
+				{Key: v1.TaintNodeNotReady, Effect: v1.TaintEffectNoExecute},
+			},
+		},
+	}
+	workloads := map[resources.ResourceReference][]v1.Toleration{
+		{Namespace: "db", Name: "mysql", Kind: "StatefulSet"}:             {{Key: "app", Operator: v1.TolerationOpEqual, Value: "db"}},
+		{Namespace: "kube-system", Name: "kube-proxy", Kind: "DaemonSet"}: {{Operator: v1.TolerationOpExists}},
+		{Namespace: "web", Name: "nginx", Kind: "Deployment"}:             {},
+	}
+	controller := true
+	pods := []v1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       "db",
+				Name:            "mysql-0",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "mysql", Controller: &controller}},
+			},
+			Status: v1.PodStatus{Phase: v1.PodRunning},
+		},
+	}
+
+	d := Node(node, workloads, pods)
+	assert.Equal(t, map[string]string{
+		v1.LabelTopologyZone:         "us-east-1a",
+		"node-role.kubernetes.io/db": "",
+		"kubernetes.io/hostname":     "db-1",
+	}, d.Labels)
+	assert.Equal(t, []Taint{
+		{
+			Taint: node.Spec.Taints[0],
+			Class: taints.ClassUser,
+			Tolerating: []resources.ResourceReference{
+				{Namespace: "db", Name: "mysql", Kind: "StatefulSet"},
+				{Namespace: "kube-system", Name: "kube-proxy", Kind: "DaemonSet"},
+			},
+		},
+		{
+			Taint: node.Spec.Taints[1],
+			Class: taints.ClassSystem,
+			Tolerating: []resources.ResourceReference{
+				{Namespace: "kube-system", Name: "kube-proxy", Kind: "DaemonSet"},
+			},
+		},
+	}, d.Taints)
+	assert.Equal(t, []Pod{
+		{ResourceReference: resources.ResourceReference{Namespace: "db", Name: "mysql-0", Kind: "Pod"}, Owner: "statefulset/mysql", Phase: v1.PodRunning},
+	}, d.Pods)
+}
+
+func TestWorkload(t *testing.T) {
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		resources.NamespaceGVR:    "NamespaceList",
+		resources.RuntimeClassGVR: "RuntimeClassList",
+		resources.DeploymentGVR:   "DeploymentList",
+	})
+
+	deployment := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"namespace": "db",
+				"name":      "mysql",
+			},
+		},
+	}
+	unstructured.SetNestedSlice(deployment.Object, []interface{}{
+		map[string]interface{}{"key": "app", "operator": "Equal", "value": "db", "effect": "NoSchedule"},
+	}, resources.TolerationPath...)
+	_, err := client.Resource(resources.DeploymentGVR).Namespace("db").Create(context.Background(), deployment, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	gvr, err := WorkloadGVRFor("Deployment")
+	assert.NoError(t, err)
+	assert.Equal(t, resources.DeploymentGVR, gvr)
+	_, err = WorkloadGVRFor("replicaset")
+	assert.Error(t, err)
+
+	effective, err := EffectiveWorkloadTolerations(client, resources.ResourceReference{Namespace: "db", Name: "mysql", Kind: "deployment"})
+	assert.NoError(t, err)
+	assert.Len(t, effective.Tolerations, 3)
+
+	nodeTaints := map[resources.ResourceReference][]v1.Taint{
+		{Name: "db-1", Kind: "Node"}:  {{Key: "app", Value: "db", Effect: v1.TaintEffectNoSchedule}},
+		{Name: "web-1", Kind: "Node"}: {{Key: "app", Value: "web", Effect: v1.TaintEffectNoSchedule}},
+		{Name: "bad-1", Kind: "Node"}: {{Key: v1.TaintNodeUnreachable, Effect: v1.TaintEffectNoExecute}},
+		{Name: "any-1", Kind: "Node"}: {},
+	}
+	d := Workload(effective, nodeTaints)
+	assert.Equal(t, resources.ResourceReference{Namespace: "db", Name: "mysql", Kind: "Deployment"}, d.ResourceReference)
+	assert.Equal(t, 4, d.Nodes)
+	assert.Equal(t, []resources.ResourceReference{{Name: "any-1", Kind: "Node"}, {Name: "bad-1", Kind: "Node"}, {Name: "db-1", Kind: "Node"}}, d.Eligible)
+
+	unlocks := make(map[resources.Source][]resources.ResourceReference)
+	for _, tol := range d.Tolerations {
+		unlocks[tol.Source] = append(unlocks[tol.Source], tol.Unlocks...)
+	}
+	assert.Equal(t, []resources.ResourceReference{{Name: "db-1", Kind: "Node"}}, unlocks[resources.SourceTemplate])
+	assert.Equal(t, []resources.ResourceReference{{Name: "bad-1", Kind: "Node"}}, unlocks[resources.SourceDefaultTolerationSeconds])
+}
+
+func TestListEffectiveTolerations(t *testing.T) {
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		resources.NamespaceGVR:    "NamespaceList",
+		resources.RuntimeClassGVR: "RuntimeClassList",
+		resources.DeploymentGVR:   "DeploymentList",
+		resources.StatefulSetGVR:  "StatefulSetList",
+		resources.DaemonSetGVR:    "DaemonSetList",
+		resources.JobGVR:          "JobList",
+		resources.CronJobGVR:      "CronJobList",
+	})
+
+	daemonSet := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "DaemonSet",
+			"metadata": map[string]interface{}{
+				"namespace": "kube-system",
+				"name":      "kube-proxy",
+			},
+		},
+	}
+	_, err := client.Resource(resources.DaemonSetGVR).Namespace("kube-system").Create(context.Background(), daemonSet, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	workloadTolerations, err := ListEffectiveTolerations(client)
+	assert.NoError(t, err)
+
+	node := v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "bad-1"},
+		Spec: v1.NodeSpec{
+			Taints: []v1.Taint{{Key: v1.TaintNodeUnschedulable, Effect: v1.TaintEffectNoSchedule}},
+		},
+	}
+	d := Node(node, workloadTolerations, nil)
+	assert.Len(t, d.Taints, 1)
+	assert.Equal(t, []resources.ResourceReference{{Namespace: "kube-system", Name: "kube-proxy", Kind: "DaemonSet"}}, d.Taints[0].Tolerating)
+}
+
+func TestListNodePods(t *testing.T) {
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		resources.PodGVR: "PodList",
+	})
+	for _, pod := range []struct{ name, node string }{{"a", "node-1"}, {"b", "node-2"}} {
+		obj := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"metadata":   map[string]interface{}{"namespace": "default", "name": pod.name},
+				"spec":       map[string]interface{}{"nodeName": pod.node},
+			},
+		}
+		_, err := client.Resource(resources.PodGVR).Namespace("default").Create(context.Background(), obj, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	pods, err := ListNodePods(client, "node-1")
+	assert.NoError(t, err)
+	assert.Len(t, pods, 1)
+	assert.Equal(t, "a", pods[0].Name)
+}
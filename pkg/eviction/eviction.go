@@ -0,0 +1,181 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eviction
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+)
+
+// Eviction is when a pod is evicted by the NoExecute taint of its node
+type Eviction struct {
+	Pod  resources.ResourceReference `json:"pod"`
+	Node string                      `json:"node"`
+	// Taint is the NoExecute taint which evicts the pod first
+	Taint v1.Taint `json:"taint"`
+	// Toleration tolerates the taint until the pod is evicted, nil when the taint is not tolerated
+	Toleration *v1.Toleration `json:"toleration,omitempty"`
+	// At is when the pod is evicted, nil when the pod tolerates every NoExecute taint forever
+	At *time.Time `json:"at,omitempty"`
+}
+
+// ListPods lists the pods which are scheduled to a node
+func ListPods(client dynamic.Interface, namespace string) ([]v1.Pod, error) {
+	pods := make([]v1.Pod, 0)
+
+	r, err := client.Resource(resources.PodGVR).Namespace(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return pods, err
+	}
+
+	for i := range r.Items {
+		var pod v1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(r.Items[i].Object, &pod); err != nil {
+			return pods, err
+		}
+		if pod.Spec.NodeName != "" {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+// Timeline returns the evictions of pods on nodes with NoExecute taints, sorted by time, pods
+// which are never evicted come last
+func Timeline(pods []v1.Pod, nodes map[resources.ResourceReference]v1.Node, now time.Time) []Eviction {
+	evictions := make([]Eviction, 0)
+	for _, pod := range pods {
+		node, ok := nodes[resources.ResourceReference{Name: pod.Spec.NodeName, Kind: "Node"}]
+		if !ok {
+			continue
+		}
+		if e, ok := Evict(pod, node.Spec.Taints, now); ok {
+			evictions = append(evictions, e)
+		}
+	}
+	Sort(evictions)
+	return evictions
+}
+
+// SimulateNodeFailure returns the evictions of the pods on a node if it became unreachable now,
+// when the node lifecycle controller adds the unreachable NoExecute taint
+func SimulateNodeFailure(node v1.Node, pods []v1.Pod, now time.Time) []Eviction {
+	added := metav1.NewTime(now)
+	nodeTaints := []v1.Taint{{Key: v1.TaintNodeUnreachable, Effect: v1.TaintEffectNoExecute, TimeAdded: &added}}
+	for _, t := range node.Spec.Taints {
+		if t.Key != v1.TaintNodeUnreachable && t.Key != v1.TaintNodeNotReady {
+			nodeTaints = append(nodeTaints, t)
+		}
+	}
+
+	evictions := make([]Eviction, 0)
+	for _, pod := range pods {
+		if pod.Spec.NodeName != node.Name {
+			continue
+		}
+		if e, ok := Evict(pod, nodeTaints, now); ok {
+			evictions = append(evictions, e)
+		}
+	}
+	Sort(evictions)
+	return evictions
+}
+
+// Evict returns when a pod is evicted by the NoExecute taints of its node, it returns false if
+// the node has no NoExecute taints. Like the taint manager each NoExecute taint is tolerated by
+// the first matching toleration of the pod, a pod is evicted when any NoExecute taint is not
+// tolerated, otherwise at the shortest tolerationSeconds of those tolerations, elapsed from the
+// time each taint was added. Taints without TimeAdded are assumed to be added now
+func Evict(pod v1.Pod, nodeTaints []v1.Taint, now time.Time) (Eviction, bool) {
+	var (
+		eviction = Eviction{
+			Pod:  resources.ResourceReference{Namespace: pod.Namespace, Name: pod.Name, Kind: "Pod"},
+			Node: pod.Spec.NodeName,
+		}
+		found bool
+	)
+
+	for i := range nodeTaints {
+		taint := nodeTaints[i]
+		if taint.Effect != v1.TaintEffectNoExecute {
+			continue
+		}
+
+		added := now
+		if taint.TimeAdded != nil {
+			added = taint.TimeAdded.Time
+		}
+
+		toleration, tolerated := firstToleration(pod.Spec.Tolerations, &taint)
+		var at *time.Time
+		switch {
+		case !tolerated:
+			at = &added
+		case toleration.TolerationSeconds != nil:
+			seconds := *toleration.TolerationSeconds
+			if seconds < 0 {
+				seconds = 0
+			}
+			t := added.Add(time.Duration(seconds) * time.Second)
+			at = &t
+		}
+
+		if !found || earlier(at, eviction.At) {
+			eviction.Taint = taint
+			eviction.Toleration = toleration
+			eviction.At = at
+		}
+		found = true
+	}
+	return eviction, found
+}
+
+// firstToleration returns the first toleration which tolerates the taint, the taint manager
+// holds a pod on a taint for the tolerationSeconds of that toleration alone
+func firstToleration(tolerations []v1.Toleration, taint *v1.Taint) (*v1.Toleration, bool) {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return &tolerations[i], true
+		}
+	}
+	return nil, false
+}
+
+// earlier returns true if a is before b, nil times are never
+func earlier(a, b *time.Time) bool {
+	if a == nil {
+		return false
+	}
+	return b == nil || a.Before(*b)
+}
+
+// Sort sorts evictions by time, then by pod
+func Sort(evictions []Eviction) {
+	sort.SliceStable(evictions, func(i, j int) bool {
+		a, b := evictions[i], evictions[j]
+		if earlier(a.At, b.At) {
+			return true
+		}
+		if earlier(b.At, a.At) {
+			return false
+		}
+		return resources.LessReference(a.Pod, b.Pod)
+	})
+}
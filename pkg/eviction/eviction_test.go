@@ -0,0 +1,170 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eviction
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEvict(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	added := metav1.NewTime(now.Add(-time.Minute))
+	notReady := v1.Taint{Key: v1.TaintNodeNotReady, Effect: v1.TaintEffectNoExecute, TimeAdded: &added}
+	maintenance := v1.Taint{Key: "maintenance", Effect: v1.TaintEffectNoExecute}
+
+	tests := []struct {
+		Description        string
+		Tolerations        []v1.Toleration
+		Taints             []v1.Taint
+		ExpectedFound      bool
+		ExpectedAt         *time.Time
+		ExpectedTaint      string
+		ExpectedToleration bool
+	}{
+		{
+			Description: "no NoExecute taints",
+			Taints:      []v1.Taint{{Key: "app", Effect: v1.TaintEffectNoSchedule}},
+		},
+		{
+			Description:   "untolerated taint evicts when it is added",
+			Taints:        []v1.Taint{notReady},
+			ExpectedFound: true,
+			ExpectedAt:    _time(now.Add(-time.Minute)),
+			ExpectedTaint: v1.TaintNodeNotReady,
+		},
+		{
+			Description:        "toleration seconds elapse from time added",
+			Tolerations:        []v1.Toleration{_toleration(v1.TaintNodeNotReady, 300)},
+			Taints:             []v1.Taint{notReady},
+			ExpectedFound:      true,
+			ExpectedAt:         _time(now.Add(4 * time.Minute)),
+			ExpectedTaint:      v1.TaintNodeNotReady,
+			ExpectedToleration: true,
+		},
+		{
+			Description:        "first matching toleration wins, exists tolerates forever",
+			Tolerations:        []v1.Toleration{{Operator: v1.TolerationOpExists}, _toleration(v1.TaintNodeNotReady, 60)},
+			Taints:             []v1.Taint{notReady},
+			ExpectedFound:      true,
+			ExpectedTaint:      v1.TaintNodeNotReady,
+			ExpectedToleration: true,
+		},
+		{
+			Description:        "first matching toleration wins, toleration seconds elapse",
+			Tolerations:        []v1.Toleration{_toleration(v1.TaintNodeNotReady, 60), {Operator: v1.TolerationOpExists}},
+			Taints:             []v1.Taint{notReady},
+			ExpectedFound:      true,
+			ExpectedAt:         _time(now),
+			ExpectedTaint:      v1.TaintNodeNotReady,
+			ExpectedToleration: true,
+		},
+		{
+			Description:        "shortest toleration of the first matching tolerations of each taint wins",
+			Tolerations:        []v1.Toleration{_toleration("maintenance", 30), _toleration(v1.TaintNodeNotReady, 300), {Operator: v1.TolerationOpExists}},
+			Taints:             []v1.Taint{notReady, maintenance},
+			ExpectedFound:      true,
+			ExpectedAt:         _time(now.Add(30 * time.Second)),
+			ExpectedTaint:      "maintenance",
+			ExpectedToleration: true,
+		},
+		{
+			Description:        "tolerated forever",
+			Tolerations:        []v1.Toleration{{Operator: v1.TolerationOpExists}},
+			Taints:             []v1.Taint{notReady, maintenance},
+			ExpectedFound:      true,
+			ExpectedTaint:      v1.TaintNodeNotReady,
+			ExpectedToleration: true,
+		},
+		{
+			Description:   "earliest taint evicts, taints without time added are added now",
+			Tolerations:   []v1.Toleration{_toleration(v1.TaintNodeNotReady, 300)},
+			Taints:        []v1.Taint{notReady, maintenance},
+			ExpectedFound: true,
+			ExpectedAt:    _time(now),
+			ExpectedTaint: "maintenance",
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		pod := v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: "mysql-0"},
+			Spec:       v1.PodSpec{NodeName: "db-1", Tolerations: test.Tolerations},
+		}
+		e, found := Evict(pod, test.Taints, now)
+		assert.Equal(t, test.ExpectedFound, found)
+		if !found {
+			continue
+		}
+		assert.Equal(t, test.ExpectedAt, e.At)
+		assert.Equal(t, test.ExpectedTaint, e.Taint.Key)
+		assert.Equal(t, test.ExpectedToleration, e.Toleration != nil)
+	}
+}
+
+func TestTimelineAndSimulateNodeFailure(t *testing.T) {
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	added := metav1.NewTime(now)
+	nodes := map[resources.ResourceReference]v1.Node{
+		{Name: "db-1", Kind: "Node"}:  _node("db-1", v1.Taint{Key: v1.TaintNodeUnreachable, Effect: v1.TaintEffectNoExecute, TimeAdded: &added}),
+		{Name: "web-1", Kind: "Node"}: _node("web-1"),
+	}
+	pods := []v1.Pod{
+		_pod("mysql-0", "db-1", _toleration(v1.TaintNodeUnreachable, 600)),
+		_pod("mysql-1", "db-1", _toleration(v1.TaintNodeUnreachable, 30)),
+		_pod("fluent-bit", "db-1", v1.Toleration{Operator: v1.TolerationOpExists}),
+		_pod("nginx", "web-1", _toleration(v1.TaintNodeUnreachable, 300)),
+	}
+
+	timeline := Timeline(pods, nodes, now)
+	assert.Equal(t, []string{"mysql-1", "mysql-0", "fluent-bit"}, _names(timeline))
+	assert.Equal(t, _time(now.Add(30*time.Second)), timeline[0].At)
+	assert.Nil(t, timeline[2].At)
+
+	simulated := SimulateNodeFailure(nodes[resources.ResourceReference{Name: "web-1", Kind: "Node"}], pods, now)
+	assert.Equal(t, []string{"nginx"}, _names(simulated))
+	assert.Equal(t, _time(now.Add(5*time.Minute)), simulated[0].At)
+}
+
+func _toleration(key string, seconds int64) v1.Toleration {
+	return v1.Toleration{Key: key, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute, TolerationSeconds: &seconds}
+}
+
+func _node(name string, taints ...v1.Taint) v1.Node {
+	return v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: v1.NodeSpec{Taints: taints}}
+}
+
+func _pod(name, node string, tolerations ...v1.Toleration) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec:       v1.PodSpec{NodeName: node, Tolerations: tolerations},
+	}
+}
+
+func _names(evictions []Eviction) []string {
+	names := make([]string, 0, len(evictions))
+	for _, e := range evictions {
+		names = append(names, e.Pod.Name)
+	}
+	return names
+}
+
+func _time(t time.Time) *time.Time {
+	return &t
+}
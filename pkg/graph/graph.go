@@ -0,0 +1,161 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/eytan-avisror/ttsum/pkg/taints"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Edge connects a workload to a taint set it tolerates, Effects are the effects of the tolerated taints
+type Edge struct {
+	Workload int              `json:"workload"`
+	TaintSet int              `json:"taintSet"`
+	Effects  []v1.TaintEffect `json:"effects"`
+}
+
+// Graph is the bipartite graph of taint sets and the workloads tolerating them, edges index
+// TaintSets and Workloads
+type Graph struct {
+	TaintSets []resources.TaintSet          `json:"taintSets"`
+	Workloads []resources.TolerationsResult `json:"workloads"`
+	Edges     []Edge                        `json:"edges"`
+}
+
+// Build connects workloads to every tainted taint set they tolerate, untainted taint sets have no
+// edges, nor have taint sets whose PreferNoSchedule taints only are not tolerated since such an edge
+// tolerates no taint
+func Build(nodeTaints map[resources.ResourceReference][]v1.Taint, workloadTolerations map[resources.ResourceReference][]v1.Toleration) *Graph {
+	g := &Graph{
+		TaintSets: resources.GroupByTaintSet(nodeTaints),
+		Workloads: resources.TolerationsResults(workloadTolerations),
+		Edges:     make([]Edge, 0),
+	}
+
+	for w, workload := range g.Workloads {
+		for s, set := range g.TaintSets {
+			if len(set.Taints) == 0 || !resources.Tolerates(workload.Tolerations, set.Taints) {
+				continue
+			}
+			effects := toleratedEffects(workload.Tolerations, set.Taints)
+			if len(effects) == 0 {
+				continue
+			}
+			g.Edges = append(g.Edges, Edge{Workload: w, TaintSet: s, Effects: effects})
+		}
+	}
+	return g
+}
+
+// WriteDot writes the graph in Graphviz DOT format
+func (g *Graph) WriteDot(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph ttsum {\n  rankdir=LR;\n")
+
+	b.WriteString("  subgraph cluster_workloads {\n    label=\"workloads\";\n")
+	for i, workload := range g.Workloads {
+		fmt.Fprintf(&b, "    w%v [shape=ellipse, label=%v];\n", i, dotQuote(workloadLabel(workload)))
+	}
+	b.WriteString("  }\n")
+
+	b.WriteString("  subgraph cluster_taintsets {\n    label=\"taint sets\";\n")
+	for i, set := range g.TaintSets {
+		fmt.Fprintf(&b, "    s%v [shape=box, label=%v];\n", i, dotQuote(taintSetLabel(set, "\n")))
+	}
+	b.WriteString("  }\n")
+
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  w%v -> s%v [label=%v];\n", e.Workload, e.TaintSet, dotQuote(effectsLabel(e.Effects)))
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+
+	b.WriteString("  subgraph workloads[Workloads]\n")
+	for i, workload := range g.Workloads {
+		fmt.Fprintf(&b, "    w%v([%v])\n", i, mermaidQuote(workloadLabel(workload)))
+	}
+	b.WriteString("  end\n")
+
+	b.WriteString("  subgraph taintsets[Taint sets]\n")
+	for i, set := range g.TaintSets {
+		fmt.Fprintf(&b, "    s%v[%v]\n", i, mermaidQuote(taintSetLabel(set, "<br/>")))
+	}
+	b.WriteString("  end\n")
+
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  w%v -- %v --> s%v\n", e.Workload, mermaidQuote(effectsLabel(e.Effects)), e.TaintSet)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// toleratedEffects returns the distinct effects of the taints which are tolerated, sorted
+func toleratedEffects(tols []v1.Toleration, ts []v1.Taint) []v1.TaintEffect {
+	seen := make(map[v1.TaintEffect]bool)
+	effects := make([]v1.TaintEffect, 0)
+	for i := range ts {
+		if resources.ToleratesTaint(tols, &ts[i]) && !seen[ts[i].Effect] {
+			seen[ts[i].Effect] = true
+			effects = append(effects, ts[i].Effect)
+		}
+	}
+	sort.Slice(effects, func(i, j int) bool {
+		return effects[i] < effects[j]
+	})
+	return effects
+}
+
+func workloadLabel(w resources.TolerationsResult) string {
+	return fmt.Sprintf("%v/%v/%v", strings.ToLower(w.Kind), w.Namespace, w.Name)
+}
+
+func taintSetLabel(set resources.TaintSet, newline string) string {
+	title := "untainted"
+	if len(set.Taints) > 0 {
+		title = taints.PrintPrettySeparated(set.Taints, newline)
+	}
+	return fmt.Sprintf("%v%v%v nodes", title, newline, len(set.Nodes))
+}
+
+func effectsLabel(effects []v1.TaintEffect) string {
+	labels := make([]string, 0, len(effects))
+	for _, e := range effects {
+		labels = append(labels, string(e))
+	}
+	return strings.Join(labels, ",")
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + strings.ReplaceAll(s, "\n", `\n`) + `"`
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
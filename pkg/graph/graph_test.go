@@ -0,0 +1,120 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func _graph() *Graph {
+	return Build(
+		map[resources.ResourceReference][]v1.Taint{
+			{Name: "db-1", Kind: "Node"}:  {{Key: "app", Value: "db", Effect: v1.TaintEffectNoSchedule}, {Key: "app", Value: "db", Effect: v1.TaintEffectNoExecute}},
+			{Name: "db-2", Kind: "Node"}:  {{Key: "app", Value: "db", Effect: v1.TaintEffectNoExecute}, {Key: "app", Value: "db", Effect: v1.TaintEffectNoSchedule}},
+			{Name: "web-1", Kind: "Node"}: {},
+		},
+		map[resources.ResourceReference][]v1.Toleration{
+			{Namespace: "default", Name: "mysql", Kind: "Deployment"}: {{Key: "app", Operator: v1.TolerationOpEqual, Value: "db"}},
+			{Namespace: "default", Name: "nginx", Kind: "Deployment"}: {},
+		},
+	)
+}
+
+func TestBuild(t *testing.T) {
+	g := _graph()
+	assert.Len(t, g.TaintSets, 2)
+	assert.Len(t, g.Workloads, 2)
+	assert.Equal(t, []Edge{
+		{Workload: 0, TaintSet: 1, Effects: []v1.TaintEffect{v1.TaintEffectNoExecute, v1.TaintEffectNoSchedule}},
+	}, g.Edges)
+}
+
+func TestBuildPreferNoSchedule(t *testing.T) {
+	g := Build(
+		map[resources.ResourceReference][]v1.Taint{
+			{Name: "spot-1", Kind: "Node"}: {{Key: "spot", Value: "true", Effect: v1.TaintEffectPreferNoSchedule}},
+			{Name: "spot-2", Kind: "Node"}: {{Key: "spot", Value: "true", Effect: v1.TaintEffectPreferNoSchedule}, {Key: "app", Value: "db", Effect: v1.TaintEffectNoSchedule}},
+		},
+		map[resources.ResourceReference][]v1.Toleration{
+			{Namespace: "default", Name: "mysql", Kind: "Deployment"}: {{Key: "app", Operator: v1.TolerationOpEqual, Value: "db"}},
+			{Namespace: "default", Name: "batch", Kind: "Job"}:        {{Key: "spot", Operator: v1.TolerationOpExists}},
+		},
+	)
+	assert.Len(t, g.TaintSets, 2)
+	assert.Equal(t, "mysql", g.Workloads[0].Name)
+	assert.Len(t, g.TaintSets[1].Taints, 1)
+	// mysql has no edge to the PreferNoSchedule only taint set it does not tolerate
+	assert.Equal(t, []Edge{
+		{Workload: 0, TaintSet: 0, Effects: []v1.TaintEffect{v1.TaintEffectNoSchedule}},
+		{Workload: 1, TaintSet: 1, Effects: []v1.TaintEffect{v1.TaintEffectPreferNoSchedule}},
+	}, g.Edges)
+
+	var b bytes.Buffer
+	assert.NoError(t, g.WriteMermaid(&b))
+	assert.NotContains(t, b.String(), `-- "" -->`)
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		Description string
+		Write       func(*Graph, *bytes.Buffer) error
+		Expected    string
+	}{
+		{
+			Description: "dot",
+			Write:       func(g *Graph, b *bytes.Buffer) error { return g.WriteDot(b) },
+			Expected: `digraph ttsum {
+  rankdir=LR;
+  subgraph cluster_workloads {
+    label="workloads";
+    w0 [shape=ellipse, label="deployment/default/mysql"];
+    w1 [shape=ellipse, label="deployment/default/nginx"];
+  }
+  subgraph cluster_taintsets {
+    label="taint sets";
+    s0 [shape=box, label="untainted\n1 nodes"];
+    s1 [shape=box, label="app=db:NoExecute\napp=db:NoSchedule\n2 nodes"];
+  }
+  w0 -> s1 [label="NoExecute,NoSchedule"];
+}
+`,
+		},
+		{
+			Description: "mermaid",
+			Write:       func(g *Graph, b *bytes.Buffer) error { return g.WriteMermaid(b) },
+			Expected: `graph LR
+  subgraph workloads[Workloads]
+    w0(["deployment/default/mysql"])
+    w1(["deployment/default/nginx"])
+  end
+  subgraph taintsets[Taint sets]
+    s0["untainted<br/>1 nodes"]
+    s1["app=db:NoExecute<br/>app=db:NoSchedule<br/>2 nodes"]
+  end
+  w0 -- "NoExecute,NoSchedule" --> s1
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		var b bytes.Buffer
+		assert.NoError(t, test.Write(_graph(), &b))
+		assert.Equal(t, test.Expected, b.String())
+	}
+}
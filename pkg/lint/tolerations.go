@@ -0,0 +1,154 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/eytan-avisror/ttsum/pkg/taints"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Rules of toleration findings
+const (
+	RuleWildcard               = "wildcard"
+	RuleAnyEffect              = "any-effect"
+	RuleNoExecuteWithoutSecond = "noexecute-without-seconds"
+	RuleDead                   = "dead"
+)
+
+// Scores of each rule, higher scores tolerate more taints
+var Scores = map[string]int{
+	RuleWildcard:               10,
+	RuleAnyEffect:              5,
+	RuleNoExecuteWithoutSecond: 3,
+	RuleDead:                   1,
+}
+
+// DefaultSystemNamespaces are namespaces where tolerating NoExecute taints indefinitely is expected
+var DefaultSystemNamespaces = []string{"kube-system", "kube-public", "kube-node-lease"}
+
+// Finding is a toleration flagged by a rule
+type Finding struct {
+	Toleration v1.Toleration `json:"toleration"`
+	Rule       string        `json:"rule"`
+	Score      int           `json:"score"`
+	Message    string        `json:"message"`
+}
+
+// Result holds the findings of a workload, Score is the sum of its findings scores
+type Result struct {
+	resources.ResourceReference
+	Score    int       `json:"score"`
+	Findings []Finding `json:"findings"`
+}
+
+// Options configure toleration linting
+type Options struct {
+	// SystemNamespaces are not flagged for NoExecute tolerations without tolerationSeconds
+	SystemNamespaces []string
+}
+
+// Tolerations scores the tolerations of each workload by breadth against the cluster node taints,
+// workloads without findings are omitted and results are sorted by descending score
+func Tolerations(objs map[resources.ResourceReference][]v1.Toleration, nodeTaints map[resources.ResourceReference][]v1.Taint, opts Options) []Result {
+	var (
+		results = make([]Result, 0)
+		system  = make(map[string]bool)
+		cluster = distinctTaints(nodeTaints)
+	)
+	for _, ns := range opts.SystemNamespaces {
+		system[ns] = true
+	}
+
+	for ref, tols := range objs {
+		result := Result{ResourceReference: ref, Findings: make([]Finding, 0)}
+		for _, tol := range tols {
+			for _, f := range lintToleration(tol, system[ref.Namespace], cluster) {
+				result.Score += f.Score
+				result.Findings = append(result.Findings, f)
+			}
+		}
+		if len(result.Findings) > 0 {
+			results = append(results, result)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return resources.LessReference(results[i].ResourceReference, results[j].ResourceReference)
+	})
+	return results
+}
+
+func lintToleration(tol v1.Toleration, systemNamespace bool, cluster []v1.Taint) []Finding {
+	findings := make([]Finding, 0)
+
+	if tol.Key == "" && tol.Operator == v1.TolerationOpExists {
+		return append(findings, finding(tol, RuleWildcard, "tolerates every taint"))
+	}
+	if tol.Effect == "" {
+		findings = append(findings, finding(tol, RuleAnyEffect, fmt.Sprintf("tolerates every effect of %v, including NoExecute", tol.Key)))
+	}
+	if tol.Effect == v1.TaintEffectNoExecute && tol.TolerationSeconds == nil && !systemNamespace {
+		findings = append(findings, finding(tol, RuleNoExecuteWithoutSecond, "stays bound to NoExecute tainted nodes indefinitely"))
+	}
+	if !toleratesAny(tol, cluster) && !isTransient(tol.Key) {
+		findings = append(findings, finding(tol, RuleDead, "matches no taint in the cluster"))
+	}
+	return findings
+}
+
+func finding(tol v1.Toleration, rule, message string) Finding {
+	return Finding{Toleration: tol, Rule: rule, Score: Scores[rule], Message: message}
+}
+
+// toleratesAny returns true if the toleration tolerates any of the taints
+func toleratesAny(tol v1.Toleration, ts []v1.Taint) bool {
+	for i := range ts {
+		if tol.ToleratesTaint(&ts[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTransient returns true for system and lifecycle taint keys, which nodes only have at times
+func isTransient(key string) bool {
+	return taints.Explain(v1.Taint{Key: key}).Class != taints.ClassUser
+}
+
+// distinctTaints returns the unique taints of all nodes
+func distinctTaints(nodeTaints map[resources.ResourceReference][]v1.Taint) []v1.Taint {
+	var (
+		seen     = make(map[v1.Taint]bool)
+		distinct = make([]v1.Taint, 0)
+	)
+	for _, ts := range nodeTaints {
+		for _, t := range ts {
+			key := v1.Taint{Key: t.Key, Value: t.Value, Effect: t.Effect}
+			if !seen[key] {
+				seen[key] = true
+				distinct = append(distinct, key)
+			}
+		}
+	}
+	sort.Slice(distinct, func(i, j int) bool {
+		return distinct[i].ToString() < distinct[j].ToString()
+	})
+	return distinct
+}
@@ -0,0 +1,87 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"testing"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestTolerations(t *testing.T) {
+	var seconds int64 = 60
+
+	nodeTaints := map[resources.ResourceReference][]v1.Taint{
+		{Name: "db-1", Kind: "Node"}:  {{Key: "app", Value: "db", Effect: v1.TaintEffectNoSchedule}},
+		{Name: "web-1", Kind: "Node"}: {{Key: "app", Value: "web", Effect: v1.TaintEffectNoExecute}},
+	}
+
+	objs := map[resources.ResourceReference][]v1.Toleration{
+		_ref("default", "agent"): {{Operator: v1.TolerationOpExists}},
+		_ref("default", "db"):    {{Key: "app", Operator: v1.TolerationOpEqual, Value: "db", Effect: v1.TaintEffectNoSchedule}},
+		_ref("default", "web"): {
+			{Key: "app", Operator: v1.TolerationOpEqual, Value: "web", Effect: v1.TaintEffectNoExecute},
+			{Key: "app", Operator: v1.TolerationOpEqual, Value: "cache", Effect: v1.TaintEffectNoSchedule},
+			{Key: v1.TaintNodeNotReady, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute, TolerationSeconds: &seconds},
+		},
+		_ref("default", "any"):          {{Key: "app", Operator: v1.TolerationOpExists}},
+		_ref("kube-system", "kube-web"): {{Key: "app", Operator: v1.TolerationOpEqual, Value: "web", Effect: v1.TaintEffectNoExecute}},
+	}
+
+	results := Tolerations(objs, nodeTaints, Options{SystemNamespaces: DefaultSystemNamespaces})
+
+	tests := []struct {
+		Description   string
+		Ref           resources.ResourceReference
+		ExpectedScore int
+		ExpectedRules []string
+	}{
+		{
+			Description:   "wildcard toleration",
+			Ref:           _ref("default", "agent"),
+			ExpectedScore: 10,
+			ExpectedRules: []string{RuleWildcard},
+		},
+		{
+			Description:   "toleration without effect",
+			Ref:           _ref("default", "any"),
+			ExpectedScore: 5,
+			ExpectedRules: []string{RuleAnyEffect},
+		},
+		{
+			Description:   "NoExecute toleration without seconds and dead toleration, transient taints are not dead",
+			Ref:           _ref("default", "web"),
+			ExpectedScore: 4,
+			ExpectedRules: []string{RuleNoExecuteWithoutSecond, RuleDead},
+		},
+	}
+
+	assert.Len(t, results, len(tests))
+	for i, test := range tests {
+		t.Log(test.Description)
+		assert.Equal(t, test.Ref, results[i].ResourceReference)
+		assert.Equal(t, test.ExpectedScore, results[i].Score)
+
+		rules := make([]string, 0)
+		for _, f := range results[i].Findings {
+			rules = append(rules, f.Rule)
+		}
+		assert.Equal(t, test.ExpectedRules, rules)
+	}
+}
+
+func _ref(namespace, name string) resources.ResourceReference {
+	return resources.ResourceReference{Namespace: namespace, Name: name, Kind: "Deployment"}
+}
@@ -0,0 +1,108 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"sort"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/eytan-avisror/ttsum/pkg/taints"
+	v1 "k8s.io/api/core/v1"
+)
+
+// UnusedTaint is a taint no workload tolerates, Class tells whether it is expected to be unused,
+// e.g. on control-plane nodes
+type UnusedTaint struct {
+	Taint v1.Taint                      `json:"taint"`
+	Class taints.Class                  `json:"class"`
+	Nodes []resources.ResourceReference `json:"nodes"`
+}
+
+// OrphanedToleration is a toleration for a taint key no node has
+type OrphanedToleration struct {
+	resources.ResourceReference
+	Toleration v1.Toleration `json:"toleration"`
+}
+
+// Unused holds the unused taints and orphaned tolerations of a cluster
+type Unused struct {
+	UnusedTaints        []UnusedTaint        `json:"unusedTaints"`
+	OrphanedTolerations []OrphanedToleration `json:"orphanedTolerations"`
+}
+
+// UnusedTaints returns the node taints no workload tolerates, sorted by taint
+func UnusedTaints(nodeTaints map[resources.ResourceReference][]v1.Taint, objs map[resources.ResourceReference][]v1.Toleration) []UnusedTaint {
+	unused := make(map[v1.Taint]*UnusedTaint)
+
+	for ref, ts := range nodeTaints {
+		for _, t := range ts {
+			key := v1.Taint{Key: t.Key, Value: t.Value, Effect: t.Effect}
+			if u, ok := unused[key]; ok {
+				u.Nodes = append(u.Nodes, ref)
+				continue
+			}
+			if toleratedByAny(key, objs) {
+				continue
+			}
+			unused[key] = &UnusedTaint{Taint: key, Class: taints.Explain(key).Class, Nodes: []resources.ResourceReference{ref}}
+		}
+	}
+
+	results := make([]UnusedTaint, 0, len(unused))
+	for _, u := range unused {
+		resources.SortReferences(u.Nodes)
+		results = append(results, *u)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Taint.ToString() < results[j].Taint.ToString()
+	})
+	return results
+}
+
+// OrphanedTolerations returns the tolerations for taint keys which no node has, tolerations for
+// system and lifecycle taints which nodes only have at times are ignored
+func OrphanedTolerations(objs map[resources.ResourceReference][]v1.Toleration, nodeTaints map[resources.ResourceReference][]v1.Taint) []OrphanedToleration {
+	keys := make(map[string]bool)
+	for _, ts := range nodeTaints {
+		for _, t := range ts {
+			keys[t.Key] = true
+		}
+	}
+
+	orphaned := make([]OrphanedToleration, 0)
+	for ref, tols := range objs {
+		for _, tol := range tols {
+			if tol.Key == "" || keys[tol.Key] || isTransient(tol.Key) {
+				continue
+			}
+			orphaned = append(orphaned, OrphanedToleration{ResourceReference: ref, Toleration: tol})
+		}
+	}
+
+	sort.SliceStable(orphaned, func(i, j int) bool {
+		if orphaned[i].ResourceReference != orphaned[j].ResourceReference {
+			return resources.LessReference(orphaned[i].ResourceReference, orphaned[j].ResourceReference)
+		}
+		return orphaned[i].Toleration.Key < orphaned[j].Toleration.Key
+	})
+	return orphaned
+}
+
+func toleratedByAny(t v1.Taint, objs map[resources.ResourceReference][]v1.Toleration) bool {
+	for _, tols := range objs {
+		if resources.ToleratesTaint(tols, &t) {
+			return true
+		}
+	}
+	return false
+}
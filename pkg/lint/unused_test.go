@@ -0,0 +1,54 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"testing"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/eytan-avisror/ttsum/pkg/taints"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestUnused(t *testing.T) {
+	controlPlane := v1.Taint{Key: "node-role.kubernetes.io/control-plane", Effect: v1.TaintEffectNoSchedule}
+	batch := v1.Taint{Key: "pool", Value: "batch", Effect: v1.TaintEffectNoSchedule}
+	db := v1.Taint{Key: "app", Value: "db", Effect: v1.TaintEffectNoSchedule}
+
+	nodeTaints := map[resources.ResourceReference][]v1.Taint{
+		{Name: "master-1", Kind: "Node"}: {controlPlane},
+		{Name: "batch-2", Kind: "Node"}:  {batch},
+		{Name: "batch-1", Kind: "Node"}:  {batch},
+		{Name: "db-1", Kind: "Node"}:     {db},
+	}
+
+	objs := map[resources.ResourceReference][]v1.Toleration{
+		_ref("default", "mysql"): {
+			{Key: "app", Operator: v1.TolerationOpEqual, Value: "db", Effect: v1.TaintEffectNoSchedule},
+			{Key: "decommissioned", Operator: v1.TolerationOpExists},
+			{Key: v1.TaintNodeNotReady, Operator: v1.TolerationOpExists},
+		},
+	}
+
+	t.Log("taints no workload tolerates are grouped by taint")
+	assert.Equal(t, []UnusedTaint{
+		{Taint: controlPlane, Class: taints.ClassSystem, Nodes: []resources.ResourceReference{{Name: "master-1", Kind: "Node"}}},
+		{Taint: batch, Class: taints.ClassUser, Nodes: []resources.ResourceReference{{Name: "batch-1", Kind: "Node"}, {Name: "batch-2", Kind: "Node"}}},
+	}, UnusedTaints(nodeTaints, objs))
+
+	t.Log("tolerations for taint keys no node has, except transient taints")
+	assert.Equal(t, []OrphanedToleration{
+		{ResourceReference: _ref("default", "mysql"), Toleration: v1.Toleration{Key: "decommissioned", Operator: v1.TolerationOpExists}},
+	}, OrphanedTolerations(objs, nodeTaints))
+}
@@ -0,0 +1,127 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+)
+
+var (
+	nodeTaintDesc = prometheus.NewDesc(
+		"ttsum_node_taint",
+		"Number of nodes with a taint",
+		[]string{"key", "value", "effect"}, nil,
+	)
+	nodesByTaintSetDesc = prometheus.NewDesc(
+		"ttsum_nodes_by_taint_set",
+		"Number of nodes sharing the exact same set of taints",
+		[]string{"taints"}, nil,
+	)
+	workloadTolerationsDesc = prometheus.NewDesc(
+		"ttsum_workload_tolerations",
+		"Number of tolerations of a workload for a taint key and effect",
+		[]string{"namespace", "kind", "name", "key", "effect"}, nil,
+	)
+	workloadEligibleNodesDesc = prometheus.NewDesc(
+		"ttsum_workload_eligible_nodes",
+		"Number of nodes whose taints are tolerated by a workload",
+		[]string{"namespace", "kind", "name"}, nil,
+	)
+	workloadsWithoutEligibleNodesDesc = prometheus.NewDesc(
+		"ttsum_workloads_without_eligible_nodes",
+		"Number of workloads which do not tolerate the taints of any node",
+		nil, nil,
+	)
+	scrapeErrorDesc = prometheus.NewDesc(
+		"ttsum_scrape_error",
+		"1 if the last collection of taints and tolerations failed",
+		nil, nil,
+	)
+)
+
+// Source provides the taints and tolerations to export, usually backed by an informer cache
+type Source interface {
+	NodeTaints() (map[resources.ResourceReference][]v1.Taint, error)
+	WorkloadTolerations(namespace string) (map[resources.ResourceReference][]v1.Toleration, error)
+}
+
+// Collector exports taint and toleration metrics computed from a Source on every scrape
+type Collector struct {
+	source    Source
+	namespace string
+}
+
+func NewCollector(source Source, namespace string) *Collector {
+	return &Collector{
+		source:    source,
+		namespace: namespace,
+	}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- nodeTaintDesc
+	ch <- nodesByTaintSetDesc
+	ch <- workloadTolerationsDesc
+	ch <- workloadEligibleNodesDesc
+	ch <- workloadsWithoutEligibleNodesDesc
+	ch <- scrapeErrorDesc
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	nodeTaints, err := c.source.NodeTaints()
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(scrapeErrorDesc, prometheus.GaugeValue, 1)
+		return
+	}
+
+	workloadTolerations, err := c.source.WorkloadTolerations(c.namespace)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(scrapeErrorDesc, prometheus.GaugeValue, 1)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(scrapeErrorDesc, prometheus.GaugeValue, 0)
+
+	taintCounts := make(map[v1.Taint]float64)
+	for _, taints := range nodeTaints {
+		for _, t := range taints {
+			taintCounts[v1.Taint{Key: t.Key, Value: t.Value, Effect: t.Effect}]++
+		}
+	}
+	for t, count := range taintCounts {
+		ch <- prometheus.MustNewConstMetric(nodeTaintDesc, prometheus.GaugeValue, count, t.Key, t.Value, string(t.Effect))
+	}
+
+	for _, set := range resources.GroupByTaintSet(nodeTaints) {
+		ch <- prometheus.MustNewConstMetric(nodesByTaintSetDesc, prometheus.GaugeValue, float64(len(set.Nodes)), set.Key)
+	}
+
+	var withoutEligibleNodes float64
+	for ref, tolerations := range workloadTolerations {
+		tolerationCounts := make(map[[2]string]float64)
+		for _, t := range tolerations {
+			tolerationCounts[[2]string{t.Key, string(t.Effect)}]++
+		}
+		for keyEffect, count := range tolerationCounts {
+			ch <- prometheus.MustNewConstMetric(workloadTolerationsDesc, prometheus.GaugeValue, count, ref.Namespace, ref.Kind, ref.Name, keyEffect[0], keyEffect[1])
+		}
+
+		eligible := len(resources.EligibleNodes(tolerations, nodeTaints))
+		if eligible == 0 {
+			withoutEligibleNodes++
+		}
+		ch <- prometheus.MustNewConstMetric(workloadEligibleNodesDesc, prometheus.GaugeValue, float64(eligible), ref.Namespace, ref.Kind, ref.Name)
+	}
+	ch <- prometheus.MustNewConstMetric(workloadsWithoutEligibleNodesDesc, prometheus.GaugeValue, withoutEligibleNodes)
+}
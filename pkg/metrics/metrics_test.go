@@ -0,0 +1,72 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+type fakeSource struct {
+	taints      map[resources.ResourceReference][]v1.Taint
+	tolerations map[resources.ResourceReference][]v1.Toleration
+}
+
+func (f *fakeSource) NodeTaints() (map[resources.ResourceReference][]v1.Taint, error) {
+	return f.taints, nil
+}
+
+func (f *fakeSource) WorkloadTolerations(namespace string) (map[resources.ResourceReference][]v1.Toleration, error) {
+	return f.tolerations, nil
+}
+
+func TestCollector(t *testing.T) {
+	source := &fakeSource{
+		taints: map[resources.ResourceReference][]v1.Taint{
+			{Name: "node-a", Kind: "Node"}: {{Key: "app", Value: "web", Effect: v1.TaintEffectNoSchedule}},
+			{Name: "node-b", Kind: "Node"}: {{Key: "app", Value: "web", Effect: v1.TaintEffectNoSchedule}},
+		},
+		tolerations: map[resources.ResourceReference][]v1.Toleration{
+			{Namespace: "default", Name: "nginx", Kind: "Deployment"}: {{Operator: v1.TolerationOpEqual, Key: "app", Value: "web", Effect: v1.TaintEffectNoSchedule}},
+			{Namespace: "default", Name: "mysql", Kind: "Deployment"}: {{Operator: v1.TolerationOpEqual, Key: "app", Value: "db", Effect: v1.TaintEffectNoSchedule}},
+		},
+	}
+
+	expected := `
+# HELP ttsum_node_taint Number of nodes with a taint
+# TYPE ttsum_node_taint gauge
+ttsum_node_taint{effect="NoSchedule",key="app",value="web"} 2
+# HELP ttsum_nodes_by_taint_set Number of nodes sharing the exact same set of taints
+# TYPE ttsum_nodes_by_taint_set gauge
+ttsum_nodes_by_taint_set{taints="app=web:NoSchedule"} 2
+# HELP ttsum_workload_eligible_nodes Number of nodes whose taints are tolerated by a workload
+# TYPE ttsum_workload_eligible_nodes gauge
+ttsum_workload_eligible_nodes{kind="Deployment",name="mysql",namespace="default"} 0
+ttsum_workload_eligible_nodes{kind="Deployment",name="nginx",namespace="default"} 2
+# HELP ttsum_workloads_without_eligible_nodes Number of workloads which do not tolerate the taints of any node
+# TYPE ttsum_workloads_without_eligible_nodes gauge
+ttsum_workloads_without_eligible_nodes 1
+`
+	err := testutil.CollectAndCompare(NewCollector(source, ""), strings.NewReader(expected),
+		"ttsum_node_taint",
+		"ttsum_nodes_by_taint_set",
+		"ttsum_workload_eligible_nodes",
+		"ttsum_workloads_without_eligible_nodes",
+	)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,329 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroups
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/eytan-avisror/ttsum/pkg/taints"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Kinds of node groups imported from infrastructure definitions
+const (
+	KindEKSNodeGroup = "EKSNodeGroup"
+	KindGKENodePool  = "GKENodePool"
+	KindAKSNodePool  = "AKSNodePool"
+)
+
+// aksAgentPoolType is the ARM resource type of AKS node pools
+const aksAgentPoolType = "Microsoft.ContainerService/managedClusters/agentPools"
+
+// terraformKinds maps Terraform resource types to node group kinds
+var terraformKinds = map[string]string{
+	"aws_eks_node_group":                   KindEKSNodeGroup,
+	"google_container_node_pool":           KindGKENodePool,
+	"azurerm_kubernetes_cluster_node_pool": KindAKSNodePool,
+}
+
+// importDocument returns the node groups of an eksctl ClusterConfig, a Terraform JSON plan, or a GKE
+// or AKS node pool as returned by gcloud and az, ok is false for other documents
+func importDocument(obj map[string]interface{}) (groups []NodeGroup, ok bool, err error) {
+	switch {
+	case isEksctl(obj):
+		groups, err = importEksctl(obj)
+	case isTerraformPlan(obj):
+		groups, err = importTerraform(obj)
+	case isGKENodePool(obj):
+		groups, err = importGKE(obj)
+	case isAKSNodePool(obj):
+		groups, err = importAKS(obj)
+	default:
+		return nil, false, nil
+	}
+	return groups, true, err
+}
+
+func isEksctl(obj map[string]interface{}) bool {
+	return obj["kind"] == "ClusterConfig" && strings.HasPrefix(fmt.Sprint(obj["apiVersion"]), "eksctl.io/")
+}
+
+// isTerraformPlan is true for the output of terraform show -json of a plan
+func isTerraformPlan(obj map[string]interface{}) bool {
+	_, planned := obj["planned_values"].(map[string]interface{})
+	_, changes := obj["resource_changes"].([]interface{})
+	return obj["format_version"] != nil && planned && changes
+}
+
+// isGKENodePool is true for a NodePool of the GKE container API, which has a NodeConfig and a
+// self link to the node pool
+func isGKENodePool(obj map[string]interface{}) bool {
+	config, ok := obj["config"].(map[string]interface{})
+	if !ok || config["machineType"] == nil {
+		return false
+	}
+	_, named := obj["name"].(string)
+	return named && strings.Contains(fmt.Sprint(obj["selfLink"]), "/nodePools/")
+}
+
+// isAKSNodePool is true for an AgentPool of the AKS container service API
+func isAKSNodePool(obj map[string]interface{}) bool {
+	_, named := obj["name"].(string)
+	return named && obj["vmSize"] != nil && strings.EqualFold(fmt.Sprint(obj["type"]), aksAgentPoolType)
+}
+
+// importEksctl returns the nodeGroups and managedNodeGroups of an eksctl ClusterConfig
+func importEksctl(obj map[string]interface{}) ([]NodeGroup, error) {
+	groups := make([]NodeGroup, 0)
+	for _, field := range []string{"nodeGroups", "managedNodeGroups"} {
+		ngs, _, err := unstructured.NestedSlice(obj, field)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ng := range ngs {
+			spec, ok := ng.(map[string]interface{})
+			if !ok {
+				return nil, errors.Errorf("invalid eksctl %v", field)
+			}
+
+			name, err := stringField(spec, "name", "eksctl "+field)
+			if err != nil {
+				return nil, err
+			}
+			group := NodeGroup{
+				ResourceReference: resources.ResourceReference{Name: name, Kind: KindEKSNodeGroup},
+				Labels:            stringMap(spec["labels"]),
+			}
+
+			// taints are either a list of taints or a map of key to value:effect
+			switch ts := spec["taints"].(type) {
+			case map[string]interface{}:
+				group.Taints = make([]v1.Taint, 0, len(ts))
+				for key, value := range ts {
+					t, parseErr := taints.Parse(fmt.Sprintf("%v=%v", key, value))
+					if parseErr != nil {
+						return nil, errors.Wrapf(parseErr, "invalid taint in eksctl node group %v", group.Name)
+					}
+					group.Taints = append(group.Taints, t)
+				}
+				sortTaints(group.Taints)
+			default:
+				group.Taints, err = taintList(spec["taints"])
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid taint in eksctl node group %v", group.Name)
+			}
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+// importTerraform returns the EKS node groups, GKE node pools and AKS node pools planned in a
+// Terraform JSON plan, including those of child modules
+func importTerraform(obj map[string]interface{}) ([]NodeGroup, error) {
+	rootModule, _, err := unstructured.NestedMap(obj, "planned_values", "root_module")
+	if err != nil {
+		return nil, err
+	}
+	return importTerraformModule(rootModule)
+}
+
+func importTerraformModule(module map[string]interface{}) ([]NodeGroup, error) {
+	groups := make([]NodeGroup, 0)
+
+	rs, _, err := unstructured.NestedSlice(module, "resources")
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rs {
+		resource, ok := r.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("invalid terraform resource")
+		}
+		kind, ok := terraformKinds[fmt.Sprint(resource["type"])]
+		if !ok {
+			continue
+		}
+		values, _ := resource["values"].(map[string]interface{})
+
+		address, err := stringField(resource, "address", "terraform resource")
+		if err != nil {
+			return nil, err
+		}
+		group := NodeGroup{ResourceReference: resources.ResourceReference{Name: address, Kind: kind}}
+		switch kind {
+		case KindEKSNodeGroup:
+			group.Labels = stringMap(values["labels"])
+			group.Taints, err = taintList(values["taint"])
+		case KindGKENodePool:
+			configs, _ := values["node_config"].([]interface{})
+			if len(configs) > 0 {
+				config, _ := configs[0].(map[string]interface{})
+				group.Labels = stringMap(config["labels"])
+				group.Taints, err = taintList(config["taint"])
+			}
+		case KindAKSNodePool:
+			group.Labels = stringMap(values["node_labels"])
+			group.Taints, err = taintStrings(values["node_taints"])
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid taint in %v", group.Name)
+		}
+		if group.Taints == nil {
+			group.Taints = make([]v1.Taint, 0)
+		}
+		groups = append(groups, group)
+	}
+
+	children, _, err := unstructured.NestedSlice(module, "child_modules")
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range children {
+		child, ok := c.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("invalid terraform child module")
+		}
+		childGroups, err := importTerraformModule(child)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, childGroups...)
+	}
+	return groups, nil
+}
+
+// importGKE returns a GKE node pool as described by the container API
+func importGKE(obj map[string]interface{}) ([]NodeGroup, error) {
+	name, err := stringField(obj, "name", "GKE node pool")
+	if err != nil {
+		return nil, err
+	}
+	config, _ := obj["config"].(map[string]interface{})
+	group := NodeGroup{
+		ResourceReference: resources.ResourceReference{Name: name, Kind: KindGKENodePool},
+		Labels:            stringMap(config["labels"]),
+	}
+
+	if group.Taints, err = taintList(config["taints"]); err != nil {
+		return nil, errors.Wrapf(err, "invalid taint in GKE node pool %v", group.Name)
+	}
+	return []NodeGroup{group}, nil
+}
+
+// importAKS returns an AKS agent pool as described by the container service API
+func importAKS(obj map[string]interface{}) ([]NodeGroup, error) {
+	name, err := stringField(obj, "name", "AKS node pool")
+	if err != nil {
+		return nil, err
+	}
+	group := NodeGroup{
+		ResourceReference: resources.ResourceReference{Name: name, Kind: KindAKSNodePool},
+		Labels:            stringMap(obj["nodeLabels"]),
+	}
+
+	if group.Taints, err = taintStrings(obj["nodeTaints"]); err != nil {
+		return nil, errors.Wrapf(err, "invalid taint in AKS node pool %v", group.Name)
+	}
+	return []NodeGroup{group}, nil
+}
+
+// taintList converts a list of taints with key, value and effect fields, effects may use the
+// NO_SCHEDULE form of cloud provider APIs
+func taintList(field interface{}) ([]v1.Taint, error) {
+	list, _ := field.([]interface{})
+	ts := make([]v1.Taint, 0, len(list))
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("invalid taint: %v", item)
+		}
+
+		key, err := stringField(m, "key", "taint")
+		if err != nil {
+			return nil, err
+		}
+		effect, err := stringField(m, "effect", "taint "+key)
+		if err != nil {
+			return nil, err
+		}
+
+		t := v1.Taint{
+			Key:    key,
+			Effect: cloudEffect(effect),
+		}
+		if value, ok := m["value"]; ok && value != nil {
+			t.Value = fmt.Sprint(value)
+		}
+		if _, err := taints.Parse(fmt.Sprintf("%v:%v", t.Key, t.Effect)); err != nil {
+			return nil, err
+		}
+		ts = append(ts, t)
+	}
+	return ts, nil
+}
+
+// taintStrings converts a list of taints in format key=value:effect
+func taintStrings(field interface{}) ([]v1.Taint, error) {
+	list, _ := field.([]interface{})
+	ts := make([]v1.Taint, 0, len(list))
+	for _, item := range list {
+		t, err := taints.Parse(fmt.Sprint(item))
+		if err != nil {
+			return nil, err
+		}
+		ts = append(ts, t)
+	}
+	return ts, nil
+}
+
+// cloudEffect converts NO_SCHEDULE, NO_EXECUTE and PREFER_NO_SCHEDULE effects
+func cloudEffect(effect string) v1.TaintEffect {
+	switch effect {
+	case "NO_SCHEDULE":
+		return v1.TaintEffectNoSchedule
+	case "NO_EXECUTE":
+		return v1.TaintEffectNoExecute
+	case "PREFER_NO_SCHEDULE":
+		return v1.TaintEffectPreferNoSchedule
+	}
+	return v1.TaintEffect(effect)
+}
+
+// stringField returns a field of a document which must be a non-empty string
+func stringField(obj map[string]interface{}, field, document string) (string, error) {
+	value, ok := obj[field].(string)
+	if !ok || value == "" {
+		return "", errors.Errorf("%v has no %v", document, field)
+	}
+	return value, nil
+}
+
+func stringMap(field interface{}) map[string]string {
+	m, ok := field.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	labels := make(map[string]string, len(m))
+	for k, v := range m {
+		labels[k] = fmt.Sprint(v)
+	}
+	return labels
+}
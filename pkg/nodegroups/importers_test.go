@@ -0,0 +1,225 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroups
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestLoadInfrastructure(t *testing.T) {
+	tests := []struct {
+		Description string
+		File        string
+		Expected    []NodeGroup
+	}{
+		{
+			Description: "eksctl cluster config",
+			File: `
+apiVersion: eksctl.io/v1alpha5
+kind: ClusterConfig
+metadata:
+  name: prod
+nodeGroups:
+- name: db
+  labels: {app: db}
+  taints:
+    app: db:NoSchedule
+managedNodeGroups:
+- name: gpu
+  taints:
+  - key: nvidia.com/gpu
+    value: "true"
+    effect: NoSchedule
+`,
+			Expected: []NodeGroup{
+				{
+					ResourceReference: resources.ResourceReference{Name: "db", Kind: KindEKSNodeGroup},
+					Taints:            []v1.Taint{_taint("app", "db", "NoSchedule")},
+					Labels:            map[string]string{"app": "db"},
+				},
+				{
+					ResourceReference: resources.ResourceReference{Name: "gpu", Kind: KindEKSNodeGroup},
+					Taints:            []v1.Taint{_taint("nvidia.com/gpu", "true", "NoSchedule")},
+				},
+			},
+		},
+		{
+			Description: "terraform plan with a child module",
+			File: `{
+  "format_version": "1.1",
+  "terraform_version": "1.5.7",
+  "resource_changes": [],
+  "planned_values": {
+    "root_module": {
+      "resources": [
+        {
+          "address": "aws_eks_node_group.web",
+          "type": "aws_eks_node_group",
+          "values": {"labels": {"app": "web"}, "taint": [{"key": "app", "value": "web", "effect": "NO_SCHEDULE"}]}
+        },
+        {"address": "aws_iam_role.nodes", "type": "aws_iam_role", "values": {}}
+      ],
+      "child_modules": [
+        {
+          "resources": [
+            {
+              "address": "module.aks.azurerm_kubernetes_cluster_node_pool.spot",
+              "type": "azurerm_kubernetes_cluster_node_pool",
+              "values": {"node_taints": ["kubernetes.azure.com/scalesetpriority=spot:NoSchedule"]}
+            }
+          ]
+        }
+      ]
+    }
+  }
+}`,
+			Expected: []NodeGroup{
+				{
+					ResourceReference: resources.ResourceReference{Name: "aws_eks_node_group.web", Kind: KindEKSNodeGroup},
+					Taints:            []v1.Taint{_taint("app", "web", "NoSchedule")},
+					Labels:            map[string]string{"app": "web"},
+				},
+				{
+					ResourceReference: resources.ResourceReference{Name: "module.aks.azurerm_kubernetes_cluster_node_pool.spot", Kind: KindAKSNodePool},
+					Taints:            []v1.Taint{_taint("kubernetes.azure.com/scalesetpriority", "spot", "NoSchedule")},
+				},
+			},
+		},
+		{
+			Description: "gcloud node pool list",
+			File:        `[{"name": "batch", "selfLink": "https://container.googleapis.com/v1/projects/p/locations/us-central1/clusters/prod/nodePools/batch", "config": {"machineType": "e2-standard-4", "labels": {"team": "data"}, "taints": [{"key": "batch", "value": "true", "effect": "NO_EXECUTE"}]}}]`,
+			Expected: []NodeGroup{
+				{
+					ResourceReference: resources.ResourceReference{Name: "batch", Kind: KindGKENodePool},
+					Taints:            []v1.Taint{_taint("batch", "true", "NoExecute")},
+					Labels:            map[string]string{"team": "data"},
+				},
+			},
+		},
+		{
+			Description: "az aks nodepool",
+			File:        `{"name": "gpu", "type": "Microsoft.ContainerService/managedClusters/agentPools", "vmSize": "Standard_NC6", "nodeTaints": ["sku=gpu:NoSchedule"], "nodeLabels": null}`,
+			Expected: []NodeGroup{
+				{
+					ResourceReference: resources.ResourceReference{Name: "gpu", Kind: KindAKSNodePool},
+					Taints:            []v1.Taint{_taint("sku", "gpu", "NoSchedule")},
+				},
+			},
+		},
+		{
+			Description: "eksctl cluster config after another document",
+			File: `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: prod
+---
+apiVersion: eksctl.io/v1alpha5
+kind: ClusterConfig
+metadata:
+  name: prod
+nodeGroups:
+- name: db
+  taints:
+    app: db:NoSchedule
+`,
+			Expected: []NodeGroup{
+				{
+					ResourceReference: resources.ResourceReference{Name: "db", Kind: KindEKSNodeGroup},
+					Taints:            []v1.Taint{_taint("app", "db", "NoSchedule")},
+				},
+			},
+		},
+		{
+			Description: "cluster config which is not eksctl",
+			File:        "apiVersion: example.com/v1\nkind: ClusterConfig\nnodeGroups:\n- name: db\n  taints:\n    app: db:NoSchedule\n",
+			Expected:    []NodeGroup{},
+		},
+		{
+			Description: "terraform state is not a plan",
+			File:        `{"format_version": "1.0", "values": {"root_module": {"resources": [{"address": "aws_eks_node_group.web", "type": "aws_eks_node_group", "values": {}}]}}}`,
+			Expected:    []NodeGroup{},
+		},
+		{
+			Description: "planned values without a terraform plan",
+			File:        `{"planned_values": {"root_module": {"resources": [{"address": "aws_eks_node_group.web", "type": "aws_eks_node_group", "values": {}}]}}}`,
+			Expected:    []NodeGroup{},
+		},
+		{
+			Description: "document with name and config",
+			File:        `{"name": "app", "config": {"debug": true, "taints": [{"key": "app", "effect": "NO_SCHEDULE"}]}}`,
+			Expected:    []NodeGroup{},
+		},
+		{
+			Description: "document with vm size and taints which is not an agent pool",
+			File:        `{"name": "vm", "vmSize": "Standard_D2", "nodeTaints": ["app=web:NoSchedule"], "nodeLabels": {"app": "web"}}`,
+			Expected:    []NodeGroup{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		path := filepath.Join(t.TempDir(), "infrastructure")
+		assert.NoError(t, os.WriteFile(path, []byte(test.File), 0644))
+
+		groups, err := Load(path)
+		assert.NoError(t, err)
+		assert.Equal(t, test.Expected, groups)
+	}
+}
+
+func TestLoadInfrastructureErrors(t *testing.T) {
+	tests := []struct {
+		Description   string
+		File          string
+		ExpectedError string
+	}{
+		{
+			Description:   "eksctl node group without a name",
+			File:          "apiVersion: eksctl.io/v1alpha5\nkind: ClusterConfig\nnodeGroups:\n- labels: {app: db}\n",
+			ExpectedError: "eksctl nodeGroups has no name",
+		},
+		{
+			Description:   "eksctl taint without a key",
+			File:          "apiVersion: eksctl.io/v1alpha5\nkind: ClusterConfig\nmanagedNodeGroups:\n- name: gpu\n  taints:\n  - value: \"true\"\n    effect: NoSchedule\n",
+			ExpectedError: "taint has no key",
+		},
+		{
+			Description:   "gcloud taint without an effect",
+			File:          `[{"name": "batch", "selfLink": "https://container.googleapis.com/v1/projects/p/locations/us-central1/clusters/prod/nodePools/batch", "config": {"machineType": "e2-standard-4", "taints": [{"key": "batch"}]}}]`,
+			ExpectedError: "taint batch has no effect",
+		},
+		{
+			Description:   "terraform resource without an address",
+			File:          `{"format_version": "1.1", "resource_changes": [], "planned_values": {"root_module": {"resources": [{"type": "aws_eks_node_group", "values": {}}]}}}`,
+			ExpectedError: "terraform resource has no address",
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		path := filepath.Join(t.TempDir(), "infrastructure")
+		assert.NoError(t, os.WriteFile(path, []byte(test.File), 0644))
+
+		_, err := Load(path)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), test.ExpectedError)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroups
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/eytan-avisror/ttsum/pkg/taints"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// TaintsAnnotation holds the taints of a Cluster API node group, in format key=value:effect separated by commas
+	TaintsAnnotation = "capacity.cluster-autoscaler.kubernetes.io/taints"
+	// LabelsAnnotation holds the labels of a Cluster API node group, in format key=value separated by commas
+	LabelsAnnotation = "capacity.cluster-autoscaler.kubernetes.io/labels"
+	// TaintTagPrefix prefixes cluster-autoscaler node template taint tags, the tag value is in format value:effect
+	TaintTagPrefix = "k8s.io/cluster-autoscaler/node-template/taint/"
+	// LabelTagPrefix prefixes cluster-autoscaler node template label tags
+	LabelTagPrefix = "k8s.io/cluster-autoscaler/node-template/label/"
+	// ConfigMapLabel marks a ConfigMap in a manifest as holding cluster-autoscaler node template tags
+	ConfigMapLabel = "ttsum/node-groups"
+)
+
+var (
+	NodePoolGVRs = []schema.GroupVersionResource{
+		{Group: "karpenter.sh", Version: "v1", Resource: "nodepools"},
+		{Group: "karpenter.sh", Version: "v1beta1", Resource: "nodepools"},
+	}
+	MachineDeploymentGVR = schema.GroupVersionResource{Group: "cluster.x-k8s.io", Version: "v1beta1", Resource: "machinedeployments"}
+	ConfigMapGVR         = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+)
+
+// NodeGroup is a group of nodes a provisioner can create, StartupTaints are removed once nodes
+// are initialized and do not need to be tolerated
+type NodeGroup struct {
+	resources.ResourceReference
+	Taints        []v1.Taint        `json:"taints"`
+	StartupTaints []v1.Taint        `json:"startupTaints,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+}
+
+// FromObject returns the node groups declared by a Karpenter NodePool, a Cluster API
+// MachineDeployment or MachineSet, or a ConfigMap labeled ConfigMapLabel=true of cluster-autoscaler
+// node template tags keyed by node group. Other objects declare no node groups
+func FromObject(obj *unstructured.Unstructured) ([]NodeGroup, error) {
+	group := NodeGroup{ResourceReference: resources.ReferenceFor(obj)}
+
+	var err error
+	switch obj.GetKind() {
+	case "NodePool":
+		if group.Taints, err = taintsAt(obj, "spec", "template", "spec", "taints"); err != nil {
+			return nil, err
+		}
+		if group.StartupTaints, err = taintsAt(obj, "spec", "template", "spec", "startupTaints"); err != nil {
+			return nil, err
+		}
+		group.Labels, _, err = unstructured.NestedStringMap(obj.Object, "spec", "template", "metadata", "labels")
+		return []NodeGroup{group}, err
+	case "MachineDeployment", "MachineSet":
+		annotations := obj.GetAnnotations()
+		if group.Taints, err = parseTaints(annotations[TaintsAnnotation]); err != nil {
+			return nil, errors.Wrapf(err, "invalid %v annotation on %v", TaintsAnnotation, group.Name)
+		}
+		group.Labels = parseLabels(annotations[LabelsAnnotation])
+		return []NodeGroup{group}, nil
+	case "ConfigMap":
+		if obj.GetLabels()[ConfigMapLabel] != "true" {
+			return nil, nil
+		}
+		return fromConfigMap(obj)
+	}
+	return nil, nil
+}
+
+// FromTags returns a node group from cluster-autoscaler node template tags
+func FromTags(ref resources.ResourceReference, tags map[string]string) (NodeGroup, error) {
+	group := NodeGroup{ResourceReference: ref, Taints: make([]v1.Taint, 0)}
+	for tag, value := range tags {
+		switch {
+		case strings.HasPrefix(tag, TaintTagPrefix):
+			t, err := taints.Parse(strings.TrimPrefix(tag, TaintTagPrefix) + "=" + value)
+			if err != nil {
+				return group, err
+			}
+			group.Taints = append(group.Taints, t)
+		case strings.HasPrefix(tag, LabelTagPrefix):
+			if group.Labels == nil {
+				group.Labels = make(map[string]string)
+			}
+			group.Labels[strings.TrimPrefix(tag, LabelTagPrefix)] = value
+		}
+	}
+	sortTaints(group.Taints)
+	return group, nil
+}
+
+// Load reads node groups from eksctl ClusterConfigs, Terraform JSON plans, GKE or AKS node pool
+// descriptions, and Kubernetes manifests, files may hold several YAML documents or JSON lists
+func Load(path string) ([]NodeGroup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]NodeGroup, 0)
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		var doc interface{}
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode %v", path)
+		}
+
+		docGroups, err := fromDocument(doc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid node group in %v", path)
+		}
+		groups = append(groups, docGroups...)
+	}
+	return groups, nil
+}
+
+// fromDocument returns the node groups of an infrastructure definition or a Kubernetes object,
+// lists are read item by item
+func fromDocument(doc interface{}) ([]NodeGroup, error) {
+	switch d := doc.(type) {
+	case []interface{}:
+		groups := make([]NodeGroup, 0)
+		for _, item := range d {
+			itemGroups, err := fromDocument(item)
+			if err != nil {
+				return nil, err
+			}
+			groups = append(groups, itemGroups...)
+		}
+		return groups, nil
+	case map[string]interface{}:
+		if groups, ok, err := importDocument(d); ok || err != nil {
+			return groups, err
+		}
+		return FromObject(&unstructured.Unstructured{Object: d})
+	}
+	return nil, nil
+}
+
+// List lists Karpenter NodePools and Cluster API MachineDeployments, resources which are not
+// served by the cluster are skipped
+func List(client dynamic.Interface) ([]NodeGroup, error) {
+	groups := make([]NodeGroup, 0)
+
+	// NodePools are listed from the first served version
+	for _, gvr := range NodePoolGVRs {
+		nodePools, err := listResource(client, gvr)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return groups, err
+		}
+		groups = append(groups, nodePools...)
+		break
+	}
+
+	machineDeployments, err := listResource(client, MachineDeploymentGVR)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return groups, err
+	}
+	return append(groups, machineDeployments...), nil
+}
+
+// GetConfigMap reads the node groups declared by a ConfigMap of cluster-autoscaler node template tags
+func GetConfigMap(client dynamic.Interface, namespace, name string) ([]NodeGroup, error) {
+	cm, err := client.Resource(ConfigMapGVR).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return fromConfigMap(cm)
+}
+
+// TaintsMap returns the taints of node groups keyed like resources.ListNodeTaints
+func TaintsMap(groups []NodeGroup) map[resources.ResourceReference][]v1.Taint {
+	taintsMap := make(map[resources.ResourceReference][]v1.Taint)
+	for _, g := range groups {
+		taintsMap[g.ResourceReference] = g.Taints
+	}
+	return taintsMap
+}
+
+// Provisionable returns the node groups whose taints are tolerated
+func Provisionable(tols []v1.Toleration, groups []NodeGroup) []NodeGroup {
+	provisionable := make([]NodeGroup, 0)
+	for _, g := range groups {
+		if resources.Tolerates(tols, g.Taints) {
+			provisionable = append(provisionable, g)
+		}
+	}
+	return provisionable
+}
+
+// fromConfigMap returns a node group for each data key of a ConfigMap, values are YAML maps of
+// cluster-autoscaler node template tags
+func fromConfigMap(cm *unstructured.Unstructured) ([]NodeGroup, error) {
+	data, _, err := unstructured.NestedStringMap(cm.Object, "data")
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	groups := make([]NodeGroup, 0, len(names))
+	for _, name := range names {
+		var tags map[string]string
+		if err := yaml.Unmarshal([]byte(data[name]), &tags); err != nil {
+			return nil, errors.Wrapf(err, "invalid node group %v in configmap %v/%v", name, cm.GetNamespace(), cm.GetName())
+		}
+
+		group, err := FromTags(resources.ResourceReference{Namespace: cm.GetNamespace(), Name: name, Kind: "NodeGroup"}, tags)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid node group %v in configmap %v/%v", name, cm.GetNamespace(), cm.GetName())
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+func taintsAt(obj *unstructured.Unstructured, path ...string) ([]v1.Taint, error) {
+	ts := make([]v1.Taint, 0)
+	res, ok, err := unstructured.NestedSlice(obj.Object, path...)
+	if !ok || err != nil {
+		return ts, err
+	}
+
+	for _, o := range res {
+		var t v1.Taint
+		convert, ok := o.(map[string]interface{})
+		if !ok {
+			return ts, errors.Errorf("invalid taint in %v", obj.GetName())
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(convert, &t); err != nil {
+			return ts, err
+		}
+		ts = append(ts, t)
+	}
+	return ts, nil
+}
+
+func parseTaints(s string) ([]v1.Taint, error) {
+	ts := make([]v1.Taint, 0)
+	for _, expr := range strings.Split(s, ",") {
+		if expr = strings.TrimSpace(expr); expr == "" {
+			continue
+		}
+		t, err := taints.Parse(expr)
+		if err != nil {
+			return ts, err
+		}
+		ts = append(ts, t)
+	}
+	return ts, nil
+}
+
+func parseLabels(s string) map[string]string {
+	labels := make(map[string]string)
+	for _, expr := range strings.Split(s, ",") {
+		if kv := strings.SplitN(strings.TrimSpace(expr), "=", 2); len(kv) == 2 {
+			labels[kv[0]] = kv[1]
+		}
+	}
+	return labels
+}
+
+func listResource(client dynamic.Interface, gvr schema.GroupVersionResource) ([]NodeGroup, error) {
+	groups := make([]NodeGroup, 0)
+
+	r, err := client.Resource(gvr).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return groups, err
+	}
+
+	for i := range r.Items {
+		objGroups, err := FromObject(&r.Items[i])
+		if err != nil {
+			return groups, err
+		}
+		groups = append(groups, objGroups...)
+	}
+	return groups, nil
+}
+
+func sortTaints(ts []v1.Taint) {
+	sort.Slice(ts, func(i, j int) bool {
+		return ts[i].ToString() < ts[j].ToString()
+	})
+}
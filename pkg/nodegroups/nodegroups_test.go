@@ -0,0 +1,214 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodegroups
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const manifests = `
+apiVersion: karpenter.sh/v1
+kind: NodePool
+metadata:
+  name: gpu
+spec:
+  template:
+    metadata:
+      labels:
+        team: ml
+    spec:
+      taints:
+      - key: nvidia.com/gpu
+        effect: NoSchedule
+      startupTaints:
+      - key: node.cilium.io/agent-not-ready
+        value: "true"
+        effect: NoExecute
+---
+apiVersion: cluster.x-k8s.io/v1beta1
+kind: MachineDeployment
+metadata:
+  name: db
+  namespace: clusters
+  annotations:
+    capacity.cluster-autoscaler.kubernetes.io/taints: app=db:NoSchedule
+    capacity.cluster-autoscaler.kubernetes.io/labels: app=db
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: node-groups
+  namespace: kube-system
+  labels:
+    ttsum/node-groups: "true"
+data:
+  web-asg: |
+    k8s.io/cluster-autoscaler/node-template/taint/app: web:NoSchedule
+    k8s.io/cluster-autoscaler/node-template/label/app: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unrelated
+  namespace: kube-system
+data:
+  config.yaml: |
+    - not a tag map
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: ignored
+`
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nodegroups.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(manifests), 0644))
+
+	groups, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []NodeGroup{
+		{
+			ResourceReference: resources.ResourceReference{Name: "gpu", Kind: "NodePool"},
+			Taints:            []v1.Taint{_taint("nvidia.com/gpu", "", "NoSchedule")},
+			StartupTaints:     []v1.Taint{_taint("node.cilium.io/agent-not-ready", "true", "NoExecute")},
+			Labels:            map[string]string{"team": "ml"},
+		},
+		{
+			ResourceReference: resources.ResourceReference{Namespace: "clusters", Name: "db", Kind: "MachineDeployment"},
+			Taints:            []v1.Taint{_taint("app", "db", "NoSchedule")},
+			Labels:            map[string]string{"app": "db"},
+		},
+		{
+			ResourceReference: resources.ResourceReference{Namespace: "kube-system", Name: "web-asg", Kind: "NodeGroup"},
+			Taints:            []v1.Taint{_taint("app", "web", "NoSchedule")},
+			Labels:            map[string]string{"app": "web"},
+		},
+	}, groups)
+}
+
+func TestList(t *testing.T) {
+	nodePool := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "karpenter.sh/v1beta1",
+		"kind":       "NodePool",
+		"metadata":   map[string]interface{}{"name": "gpu"},
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"taints": []interface{}{map[string]interface{}{"key": "nvidia.com/gpu", "effect": "NoSchedule"}},
+		}}},
+	}}
+	machineDeployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cluster.x-k8s.io/v1beta1",
+		"kind":       "MachineDeployment",
+		"metadata": map[string]interface{}{
+			"name":        "db",
+			"namespace":   "clusters",
+			"annotations": map[string]interface{}{TaintsAnnotation: "app=db:NoSchedule"},
+		},
+	}}
+
+	tests := []struct {
+		Description string
+		NotServed   []schema.GroupVersionResource
+		Expected    []string
+	}{
+		{
+			Description: "nodepools are listed from the first served version",
+			NotServed:   []schema.GroupVersionResource{NodePoolGVRs[0]},
+			Expected:    []string{"gpu", "db"},
+		},
+		{
+			Description: "missing resources are skipped",
+			NotServed:   append([]schema.GroupVersionResource{MachineDeploymentGVR}, NodePoolGVRs...),
+			Expected:    []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			NodePoolGVRs[0]:      "NodePoolList",
+			NodePoolGVRs[1]:      "NodePoolList",
+			MachineDeploymentGVR: "MachineDeploymentList",
+		}, nodePool, machineDeployment)
+		for _, gvr := range test.NotServed {
+			gvr := gvr
+			client.PrependReactor("list", gvr.Resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if action.GetResource() != gvr {
+					return false, nil, nil
+				}
+				return true, nil, apierrors.NewNotFound(gvr.GroupResource(), "")
+			})
+		}
+
+		groups, err := List(client)
+		assert.NoError(t, err)
+		names := make([]string, 0)
+		for _, g := range groups {
+			names = append(names, g.Name)
+		}
+		assert.Equal(t, test.Expected, names)
+	}
+}
+
+func TestProvisionable(t *testing.T) {
+	groups := []NodeGroup{
+		{ResourceReference: resources.ResourceReference{Name: "gpu", Kind: "NodePool"}, Taints: []v1.Taint{_taint("nvidia.com/gpu", "", "NoSchedule")}},
+		{ResourceReference: resources.ResourceReference{Name: "default", Kind: "NodePool"}, Taints: []v1.Taint{}},
+		{ResourceReference: resources.ResourceReference{Name: "db", Kind: "NodePool"}, Taints: []v1.Taint{_taint("app", "db", "NoSchedule")}},
+	}
+
+	tests := []struct {
+		Description string
+		Tolerations []v1.Toleration
+		Expected    []string
+	}{
+		{
+			Description: "no tolerations",
+			Expected:    []string{"default"},
+		},
+		{
+			Description: "gpu toleration",
+			Tolerations: []v1.Toleration{{Key: "nvidia.com/gpu", Operator: v1.TolerationOpExists}},
+			Expected:    []string{"gpu", "default"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		names := make([]string, 0)
+		for _, g := range Provisionable(test.Tolerations, groups) {
+			names = append(names, g.Name)
+		}
+		assert.Equal(t, test.Expected, names)
+	}
+}
+
+func _taint(key, value, effect string) v1.Taint {
+	return v1.Taint{
+		Key:    key,
+		Value:  value,
+		Effect: v1.TaintEffect(effect),
+	}
+}
@@ -0,0 +1,230 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/eytan-avisror/ttsum/pkg/taints"
+	"github.com/eytan-avisror/ttsum/pkg/tolerations"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigMapKey is the ConfigMap data key holding a policy
+const ConfigMapKey = "policy.yaml"
+
+var configMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// Policy restricts which namespaces may tolerate reserved taints, and which tolerations are
+// added to or allowed in namespaces
+type Policy struct {
+	Rules      []Rule            `json:"rules,omitempty"`
+	Namespaces []NamespacePolicy `json:"namespaces,omitempty"`
+}
+
+// NamespacePolicy applies to namespaces matching NamespaceSelector, DefaultTolerations are merged
+// into pods and pod templates and when Whitelist is set any other toleration is rejected.
+// Tolerations are in the format Operator(key=value:effect)
+type NamespacePolicy struct {
+	Name               string                `json:"name,omitempty"`
+	NamespaceSelector  *metav1.LabelSelector `json:"namespaceSelector"`
+	DefaultTolerations []string              `json:"defaultTolerations,omitempty"`
+	Whitelist          []string              `json:"whitelist,omitempty"`
+
+	defaults  []v1.Toleration
+	whitelist []v1.Toleration
+	selector  labels.Selector
+}
+
+// Rule allows only namespaces matching NamespaceSelector to tolerate Taint, Taint is in the format
+// key=value:effect where value and effect are optional and match any value or effect when omitted.
+// A rule without a NamespaceSelector does not allow any namespace to tolerate Taint
+type Rule struct {
+	Name              string                `json:"name,omitempty"`
+	Taint             string                `json:"taint"`
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector"`
+
+	taint    v1.Taint
+	selector labels.Selector
+}
+
+// Violation is a toleration which is not allowed by a rule
+type Violation struct {
+	Rule       *Rule
+	Toleration v1.Toleration
+	Namespace  string
+}
+
+func (v Violation) String() string {
+	allowed := "no namespace"
+	if v.Rule.NamespaceSelector != nil {
+		allowed = fmt.Sprintf("only namespaces matching %v", v.Rule.selector.String())
+	}
+
+	msg := fmt.Sprintf("toleration %v is not allowed in namespace %v: %v may tolerate %v",
+		tolerations.PrintPretty([]v1.Toleration{v.Toleration}),
+		v.Namespace,
+		allowed,
+		v.Rule.Taint,
+	)
+	if v.Rule.Name != "" {
+		msg = fmt.Sprintf("%v (rule %v)", msg, v.Rule.Name)
+	}
+	return msg
+}
+
+// Load reads a policy from a YAML or JSON file
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates a YAML or JSON policy
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.UnmarshalStrict(data, &p); err != nil {
+		return nil, errors.Wrap(err, "failed to decode policy")
+	}
+
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+
+		t, err := taints.Parse(rule.Taint)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid rule %v", i)
+		}
+		rule.taint = t
+
+		rule.selector, err = metav1.LabelSelectorAsSelector(rule.NamespaceSelector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid namespaceSelector in rule %v", i)
+		}
+	}
+
+	for i := range p.Namespaces {
+		ns := &p.Namespaces[i]
+
+		var err error
+		ns.selector, err = metav1.LabelSelectorAsSelector(ns.NamespaceSelector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid namespaceSelector in namespace policy %v", i)
+		}
+
+		ns.defaults, err = parseTolerations(ns.DefaultTolerations)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid defaultTolerations in namespace policy %v", i)
+		}
+
+		ns.whitelist, err = parseTolerations(ns.Whitelist)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid whitelist in namespace policy %v", i)
+		}
+	}
+	return &p, nil
+}
+
+// LoadConfigMap reads a policy from the ConfigMapKey of a ConfigMap
+func LoadConfigMap(client dynamic.Interface, namespace, name string) (*Policy, error) {
+	cm, err := client.Resource(configMapGVR).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok, err := unstructured.NestedString(cm.Object, "data", ConfigMapKey)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.Errorf("configmap %v/%v has no %v key", namespace, name, ConfigMapKey)
+	}
+	return Parse([]byte(data))
+}
+
+// NamespaceTolerations returns the default and whitelisted tolerations of every namespace policy
+// matching the labels, restricted is true if any matching policy has a whitelist
+func (p *Policy) NamespaceTolerations(namespaceLabels map[string]string) (defaults, whitelist []v1.Toleration, restricted bool) {
+	for _, ns := range p.Namespaces {
+		if !ns.selector.Matches(labels.Set(namespaceLabels)) {
+			continue
+		}
+		defaults = tolerations.Merge(defaults, ns.defaults)
+		whitelist = append(whitelist, ns.whitelist...)
+		if len(ns.Whitelist) > 0 {
+			restricted = true
+		}
+	}
+	return defaults, whitelist, restricted
+}
+
+// Evaluate returns the tolerations which are not allowed in a namespace with the given labels
+func (p *Policy) Evaluate(namespace string, namespaceLabels map[string]string, tols []v1.Toleration) []Violation {
+	violations := make([]Violation, 0)
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.selector.Matches(labels.Set(namespaceLabels)) {
+			continue
+		}
+		for _, tol := range tols {
+			if Reaches(tol, rule.taint) {
+				violations = append(violations, Violation{
+					Rule:       rule,
+					Toleration: tol,
+					Namespace:  namespace,
+				})
+			}
+		}
+	}
+	return violations
+}
+
+func parseTolerations(exprs []string) ([]v1.Toleration, error) {
+	tols := make([]v1.Toleration, 0, len(exprs))
+	for _, expr := range exprs {
+		t, err := tolerations.Parse(expr)
+		if err != nil {
+			return nil, err
+		}
+		tols = append(tols, t)
+	}
+	return tols, nil
+}
+
+// Reaches returns true if a toleration tolerates some taint described by t, an empty value or
+// effect in t stands for any value or effect
+func Reaches(tol v1.Toleration, t v1.Taint) bool {
+	if tol.Effect != "" && t.Effect != "" && tol.Effect != t.Effect {
+		return false
+	}
+	if tol.Key == "" {
+		return tol.Operator == v1.TolerationOpExists
+	}
+	if tol.Key != t.Key {
+		return false
+	}
+	if t.Value == "" || tol.Operator == v1.TolerationOpExists {
+		return true
+	}
+	return tol.Value == t.Value
+}
@@ -0,0 +1,246 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+const testPolicy = `
+rules:
+- name: gpu
+  taint: nvidia.com/gpu
+  namespaceSelector:
+    matchLabels:
+      tier: gpu
+- name: reserved
+  taint: dedicated=infra:NoSchedule
+namespaces:
+- name: gpu
+  namespaceSelector:
+    matchLabels:
+      tier: gpu
+  defaultTolerations:
+  - Exists(nvidia.com/gpu:NoSchedule)
+  whitelist:
+  - Exists(nvidia.com/gpu)
+- name: all
+  namespaceSelector: {}
+  defaultTolerations:
+  - Exists(nvidia.com/gpu)
+`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		Description string
+		Input       string
+		ExpectError bool
+	}{
+		{
+			Description: "valid policy",
+			Input:       testPolicy,
+		},
+		{
+			Description: "unknown field",
+			Input:       "rules:\n- taint: app\n  selector: {}\n",
+			ExpectError: true,
+		},
+		{
+			Description: "invalid rule taint",
+			Input:       "rules:\n- taint: app:NoRun\n",
+			ExpectError: true,
+		},
+		{
+			Description: "invalid rule namespaceSelector",
+			Input:       "rules:\n- taint: app\n  namespaceSelector:\n    matchExpressions:\n    - key: tier\n      operator: Like\n",
+			ExpectError: true,
+		},
+		{
+			Description: "invalid default toleration",
+			Input:       "namespaces:\n- namespaceSelector: {}\n  defaultTolerations:\n  - Equal(app=web:NoRun)\n",
+			ExpectError: true,
+		},
+		{
+			Description: "invalid whitelist toleration",
+			Input:       "namespaces:\n- namespaceSelector: {}\n  whitelist:\n  - Exists(app:NoRun)\n",
+			ExpectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		_, err := Parse([]byte(test.Input))
+		if test.ExpectError {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+	}
+}
+
+func TestReaches(t *testing.T) {
+	tests := []struct {
+		Description string
+		Toleration  v1.Toleration
+		Taint       v1.Taint
+		Expected    bool
+	}{
+		{
+			Description: "exists everything reaches any taint",
+			Toleration:  _toleration("Exists", "", "", ""),
+			Taint:       _taint("nvidia.com/gpu", "", ""),
+			Expected:    true,
+		},
+		{
+			Description: "equal without key reaches nothing",
+			Toleration:  _toleration("Equal", "", "", ""),
+			Taint:       _taint("nvidia.com/gpu", "", ""),
+			Expected:    false,
+		},
+		{
+			Description: "same key reaches any value",
+			Toleration:  _toleration("Equal", "nvidia.com/gpu", "true", "NoSchedule"),
+			Taint:       _taint("nvidia.com/gpu", "", ""),
+			Expected:    true,
+		},
+		{
+			Description: "different key",
+			Toleration:  _toleration("Exists", "app", "", ""),
+			Taint:       _taint("nvidia.com/gpu", "", ""),
+			Expected:    false,
+		},
+		{
+			Description: "different value",
+			Toleration:  _toleration("Equal", "dedicated", "web", ""),
+			Taint:       _taint("dedicated", "infra", ""),
+			Expected:    false,
+		},
+		{
+			Description: "exists reaches any value",
+			Toleration:  _toleration("Exists", "dedicated", "", ""),
+			Taint:       _taint("dedicated", "infra", "NoSchedule"),
+			Expected:    true,
+		},
+		{
+			Description: "different effect",
+			Toleration:  _toleration("Exists", "dedicated", "", "NoExecute"),
+			Taint:       _taint("dedicated", "infra", "NoSchedule"),
+			Expected:    false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		assert.Equal(t, test.Expected, Reaches(test.Toleration, test.Taint))
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	p, err := Parse([]byte(testPolicy))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		Description     string
+		NamespaceLabels map[string]string
+		Tolerations     []v1.Toleration
+		ExpectedRules   []string
+	}{
+		{
+			Description:     "gpu toleration in gpu namespace",
+			NamespaceLabels: map[string]string{"tier": "gpu"},
+			Tolerations:     []v1.Toleration{_toleration("Exists", "nvidia.com/gpu", "", "NoSchedule")},
+			ExpectedRules:   []string{},
+		},
+		{
+			Description:     "gpu toleration in cpu namespace",
+			NamespaceLabels: map[string]string{"tier": "cpu"},
+			Tolerations:     []v1.Toleration{_toleration("Exists", "nvidia.com/gpu", "", "NoSchedule")},
+			ExpectedRules:   []string{"gpu"},
+		},
+		{
+			Description:     "rule without selector allows no namespace",
+			NamespaceLabels: map[string]string{"tier": "gpu"},
+			Tolerations:     []v1.Toleration{_toleration("Equal", "dedicated", "infra", "NoSchedule")},
+			ExpectedRules:   []string{"reserved"},
+		},
+		{
+			Description:     "wildcard toleration violates every rule",
+			NamespaceLabels: map[string]string{"tier": "cpu"},
+			Tolerations:     []v1.Toleration{_toleration("Exists", "", "", "")},
+			ExpectedRules:   []string{"gpu", "reserved"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		rules := make([]string, 0)
+		for _, v := range p.Evaluate("test", test.NamespaceLabels, test.Tolerations) {
+			rules = append(rules, v.Rule.Name)
+		}
+		assert.Equal(t, test.ExpectedRules, rules)
+	}
+}
+
+func TestNamespaceTolerations(t *testing.T) {
+	p, err := Parse([]byte(testPolicy))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		Description        string
+		NamespaceLabels    map[string]string
+		ExpectedDefaults   []v1.Toleration
+		ExpectedWhitelist  []v1.Toleration
+		ExpectedRestricted bool
+	}{
+		{
+			Description:        "gpu namespace merges defaults of both policies",
+			NamespaceLabels:    map[string]string{"tier": "gpu"},
+			ExpectedDefaults:   []v1.Toleration{_toleration("Exists", "nvidia.com/gpu", "", "")},
+			ExpectedWhitelist:  []v1.Toleration{_toleration("Exists", "nvidia.com/gpu", "", "")},
+			ExpectedRestricted: true,
+		},
+		{
+			Description:      "other namespace is not restricted",
+			NamespaceLabels:  map[string]string{"tier": "cpu"},
+			ExpectedDefaults: []v1.Toleration{_toleration("Exists", "nvidia.com/gpu", "", "")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		defaults, whitelist, restricted := p.NamespaceTolerations(test.NamespaceLabels)
+		assert.Equal(t, test.ExpectedDefaults, defaults)
+		assert.Equal(t, test.ExpectedWhitelist, whitelist)
+		assert.Equal(t, test.ExpectedRestricted, restricted)
+	}
+}
+
+func _toleration(operator, key, value, effect string) v1.Toleration {
+	return v1.Toleration{
+		Operator: v1.TolerationOperator(operator),
+		Key:      key,
+		Value:    value,
+		Effect:   v1.TaintEffect(effect),
+	}
+}
+
+func _taint(key, value, effect string) v1.Taint {
+	return v1.Taint{
+		Key:    key,
+		Value:  value,
+		Effect: v1.TaintEffect(effect),
+	}
+}
@@ -0,0 +1,240 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+const (
+	JSONPathPrefix      = "jsonpath="
+	GoTemplatePrefix    = "go-template="
+	CustomColumnsPrefix = "custom-columns="
+
+	// none is printed by custom columns for missing fields, like kubectl
+	none = "<none>"
+)
+
+// Printer prints a slice of results
+type Printer interface {
+	Print(w io.Writer, results interface{}) error
+}
+
+// IsTemplate returns true if format is a jsonpath, go-template or custom-columns output format
+func IsTemplate(format string) bool {
+	for _, prefix := range []string{JSONPathPrefix, GoTemplatePrefix, CustomColumnsPrefix} {
+		if strings.HasPrefix(format, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// New returns the printer of a jsonpath=, go-template= or custom-columns= output format. Results are
+// printed through their JSON representation, jsonpath and go-template receive them as the items of
+// a list, e.g. jsonpath='{.items[*].name}', custom-columns are evaluated for each result,
+// e.g. custom-columns=NAME:.name,KEYS:.tolerations[*].key. Like kubectl a single object is not
+// wrapped in a list, and custom-columns print it as a single row
+func New(format string) (Printer, error) {
+	switch {
+	case strings.HasPrefix(format, JSONPathPrefix):
+		expr := strings.TrimPrefix(format, JSONPathPrefix)
+		p := jsonpath.New("output").AllowMissingKeys(true)
+		if err := p.Parse(expr); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse jsonpath %v", expr)
+		}
+		return &jsonPathPrinter{parser: p}, nil
+	case strings.HasPrefix(format, GoTemplatePrefix):
+		text := strings.TrimPrefix(format, GoTemplatePrefix)
+		t, err := template.New("output").Parse(text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse go-template %v", text)
+		}
+		return &goTemplatePrinter{template: t}, nil
+	case strings.HasPrefix(format, CustomColumnsPrefix):
+		return newCustomColumnsPrinter(strings.TrimPrefix(format, CustomColumnsPrefix))
+	}
+	return nil, errors.Errorf("unsupported output format %v", format)
+}
+
+type jsonPathPrinter struct {
+	parser *jsonpath.JSONPath
+}
+
+func (p *jsonPathPrinter) Print(w io.Writer, results interface{}) error {
+	list, err := toList(results)
+	if err != nil {
+		return err
+	}
+	return p.parser.Execute(w, list)
+}
+
+type goTemplatePrinter struct {
+	template *template.Template
+}
+
+func (p *goTemplatePrinter) Print(w io.Writer, results interface{}) error {
+	list, err := toList(results)
+	if err != nil {
+		return err
+	}
+	return p.template.Execute(w, list)
+}
+
+type column struct {
+	header string
+	parser *jsonpath.JSONPath
+}
+
+type customColumnsPrinter struct {
+	columns []column
+}
+
+func newCustomColumnsPrinter(spec string) (*customColumnsPrinter, error) {
+	if spec == "" {
+		return nil, errors.New("custom-columns format must specify at least one column")
+	}
+
+	printer := &customColumnsPrinter{}
+	for _, field := range strings.Split(spec, ",") {
+		parts := strings.SplitN(field, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.Errorf("unexpected custom-columns spec %v, expected <header>:<json-path-expr>", field)
+		}
+
+		expr, err := relaxedJSONPath(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		p := jsonpath.New(parts[0]).AllowMissingKeys(true)
+		if err := p.Parse(expr); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse jsonpath %v", parts[1])
+		}
+		printer.columns = append(printer.columns, column{header: parts[0], parser: p})
+	}
+	return printer, nil
+}
+
+func (p *customColumnsPrinter) Print(w io.Writer, results interface{}) error {
+	items, err := toItems(results)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 5, 8, 3, ' ', 0)
+	headers := make([]string, 0, len(p.columns))
+	for _, c := range p.columns {
+		headers = append(headers, c.header)
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+	for _, item := range items {
+		cells := make([]string, 0, len(p.columns))
+		for _, c := range p.columns {
+			values, err := c.parser.FindResults(item)
+			if err != nil {
+				return errors.Wrapf(err, "failed to evaluate column %v", c.header)
+			}
+			cells = append(cells, columnValue(values))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// columnValue joins the values found by a column expression with commas
+func columnValue(results [][]reflect.Value) string {
+	values := make([]string, 0)
+	for _, result := range results {
+		for _, v := range result {
+			if !v.IsValid() || (v.Kind() == reflect.Interface && v.IsNil()) {
+				continue
+			}
+			values = append(values, fmt.Sprint(v.Interface()))
+		}
+	}
+	if len(values) == 0 {
+		return none
+	}
+	return strings.Join(values, ",")
+}
+
+var relaxedJSONPathRegexp = regexp.MustCompile(`^\{?(\.?[^{}]*)\}?$`)
+
+// relaxedJSONPath accepts the column expressions kubectl accepts, with or without braces
+// and the leading dot
+func relaxedJSONPath(expr string) (string, error) {
+	submatches := relaxedJSONPathRegexp.FindStringSubmatch(expr)
+	if submatches == nil {
+		return "", errors.Errorf("unexpected jsonpath %v, expected a single expression like .name or {.name}", expr)
+	}
+	path := submatches[1]
+	if !strings.HasPrefix(path, ".") {
+		path = "." + path
+	}
+	return "{" + path + "}", nil
+}
+
+// toGeneric converts results to their generic JSON representation, a list or a single object
+func toGeneric(results interface{}) (interface{}, error) {
+	raw, err := json.Marshal(results)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal results")
+	}
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal results")
+	}
+	switch generic.(type) {
+	case []interface{}, map[string]interface{}:
+		return generic, nil
+	}
+	return nil, errors.New("results must be a list or an object")
+}
+
+// toItems converts results to the generic JSON representation of each result
+func toItems(results interface{}) ([]interface{}, error) {
+	generic, err := toGeneric(results)
+	if err != nil {
+		return nil, err
+	}
+	if items, ok := generic.([]interface{}); ok {
+		return items, nil
+	}
+	return []interface{}{generic}, nil
+}
+
+// toList wraps list results in a list like kubectl, so templates address them as .items
+func toList(results interface{}) (interface{}, error) {
+	generic, err := toGeneric(results)
+	if err != nil {
+		return nil, err
+	}
+	if items, ok := generic.([]interface{}); ok {
+		return map[string]interface{}{
+			"kind":  "List",
+			"items": items,
+		}, nil
+	}
+	return generic, nil
+}
@@ -0,0 +1,150 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestPrint(t *testing.T) {
+	results := []resources.TolerationsResult{
+		{
+			ResourceReference: resources.ResourceReference{Namespace: "default", Name: "nginx", Kind: "Deployment"},
+			Tolerations: []v1.Toleration{
+				{Key: "app", Operator: v1.TolerationOpEqual, Value: "web", Effect: v1.TaintEffectNoSchedule},
+				{Key: "nvidia.com/gpu", Operator: v1.TolerationOpExists},
+			},
+		},
+		{
+			ResourceReference: resources.ResourceReference{Namespace: "default", Name: "mysql", Kind: "Deployment"},
+			Tolerations:       []v1.Toleration{},
+		},
+	}
+
+	tests := []struct {
+		Description string
+		Format      string
+		Expected    string
+		ExpectError bool
+	}{
+		{
+			Description: "jsonpath over items",
+			Format:      "jsonpath={.items[*].name}",
+			Expected:    "nginx mysql",
+		},
+		{
+			Description: "jsonpath range",
+			Format:      `jsonpath={range .items[*]}{.name}={.tolerations[*].key}{"\n"}{end}`,
+			Expected:    "nginx=app nvidia.com/gpu\nmysql=\n",
+		},
+		{
+			Description: "go-template",
+			Format:      "go-template={{range .items}}{{.namespace}}/{{.name}} {{len .tolerations}}\n{{end}}",
+			Expected:    "default/nginx 2\ndefault/mysql 0\n",
+		},
+		{
+			Description: "custom-columns",
+			Format:      "custom-columns=NAME:.name,KEYS:.tolerations[*].key",
+			Expected:    "NAME    KEYS\nnginx   app,nvidia.com/gpu\nmysql   <none>\n",
+		},
+		{
+			Description: "custom-columns with braces",
+			Format:      "custom-columns=NAME:{.name}",
+			Expected:    "NAME\nnginx\nmysql\n",
+		},
+		{
+			Description: "custom-columns without expression",
+			Format:      "custom-columns=NAME",
+			ExpectError: true,
+		},
+		{
+			Description: "invalid jsonpath",
+			Format:      "jsonpath={.items[",
+			ExpectError: true,
+		},
+		{
+			Description: "unsupported format",
+			Format:      "yaml",
+			ExpectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		printer, err := New(test.Format)
+		if test.ExpectError {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+
+		var buf bytes.Buffer
+		assert.NoError(t, printer.Print(&buf, results))
+		assert.Equal(t, test.Expected, buf.String())
+	}
+}
+
+func TestPrintObject(t *testing.T) {
+	result := struct {
+		Name  string   `json:"name"`
+		Nodes []string `json:"nodes"`
+	}{Name: "gpu", Nodes: []string{"node-1", "node-2"}}
+
+	tests := []struct {
+		Description string
+		Format      string
+		Expected    string
+	}{
+		{
+			Description: "jsonpath over the object",
+			Format:      "jsonpath={.name}: {.nodes[*]}",
+			Expected:    "gpu: node-1 node-2",
+		},
+		{
+			Description: "go-template over the object",
+			Format:      "go-template={{.name}} {{len .nodes}}",
+			Expected:    "gpu 2",
+		},
+		{
+			Description: "custom-columns print a single row",
+			Format:      "custom-columns=NAME:.name,NODES:.nodes[*]",
+			Expected:    "NAME   NODES\ngpu    node-1,node-2\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		printer, err := New(test.Format)
+		assert.NoError(t, err)
+
+		var buf bytes.Buffer
+		assert.NoError(t, printer.Print(&buf, result))
+		assert.Equal(t, test.Expected, buf.String())
+	}
+
+	printer, err := New("jsonpath={.items}")
+	assert.NoError(t, err)
+	assert.Error(t, printer.Print(&bytes.Buffer{}, "gpu"))
+}
+
+func TestIsTemplate(t *testing.T) {
+	assert.True(t, IsTemplate("jsonpath={.items}"))
+	assert.True(t, IsTemplate("go-template={{.}}"))
+	assert.True(t, IsTemplate("custom-columns=NAME:.name"))
+	assert.False(t, IsTemplate("markdown"))
+}
@@ -0,0 +1,137 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/eytan-avisror/ttsum/pkg/coverage"
+	"github.com/eytan-avisror/ttsum/pkg/lint"
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/eytan-avisror/ttsum/pkg/taints"
+	"github.com/eytan-avisror/ttsum/pkg/tolerations"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+//go:embed report.html.tmpl
+var reportTemplate string
+
+var funcs = template.FuncMap{
+	"taints": func(ts []v1.Taint) []string {
+		return strings.Split(taints.PrintPretty(ts), taints.Separator)
+	},
+	"tolerations": func(tols []v1.Toleration) []string {
+		return strings.Split(tolerations.PrintPretty(tols), tolerations.Separator)
+	},
+	"class": func(t v1.Taint) taints.Class {
+		return taints.Explain(t).Class
+	},
+	"names": func(refs []resources.ResourceReference) string {
+		names := make([]string, 0, len(refs))
+		for _, ref := range refs {
+			names = append(names, ref.Name)
+		}
+		return strings.Join(names, ", ")
+	},
+	"list": func(tol v1.Toleration) []v1.Toleration {
+		return []v1.Toleration{tol}
+	},
+	"taintList": func(t v1.Taint) []v1.Taint {
+		return []v1.Taint{t}
+	},
+}
+
+// Input is the cluster state a report is generated from, ClusterTolerations holds the workloads of
+// every namespace when WorkloadTolerations is limited to some, so taints tolerated elsewhere are not unused
+type Input struct {
+	Source              string
+	Nodes               map[resources.ResourceReference]v1.Node
+	WorkloadTolerations map[resources.ResourceReference][]v1.Toleration
+	ClusterTolerations  map[resources.ResourceReference][]v1.Toleration
+	DaemonSets          []appsv1.DaemonSet
+	DaemonSetPods       map[resources.ResourceReference]int
+	// DaemonSetOptions hold the namespace and RuntimeClass tolerations of DaemonSets
+	DaemonSetOptions resources.EffectiveOptions
+	Lint             lint.Options
+}
+
+// Row is a workload of the compatibility matrix, Tolerated holds whether each taint set is tolerated
+type Row struct {
+	resources.TolerationsResult
+	Tolerated []bool
+}
+
+// Report is the content of an HTML report
+type Report struct {
+	Source              string
+	GeneratedAt         time.Time
+	Nodes               int
+	TaintSets           []resources.TaintSet
+	Matrix              []Row
+	Lint                []lint.Result
+	UnusedTaints        []lint.UnusedTaint
+	OrphanedTolerations []lint.OrphanedToleration
+	Coverage            []coverage.Report
+}
+
+// New computes a report
+func New(in Input) *Report {
+	nodeTaints := make(map[resources.ResourceReference][]v1.Taint)
+	for ref, node := range in.Nodes {
+		nodeTaints[ref] = node.Spec.Taints
+	}
+
+	clusterTolerations := in.ClusterTolerations
+	if clusterTolerations == nil {
+		clusterTolerations = in.WorkloadTolerations
+	}
+
+	r := &Report{
+		Source:              in.Source,
+		GeneratedAt:         time.Now().UTC(),
+		Nodes:               len(in.Nodes),
+		TaintSets:           resources.GroupByTaintSet(nodeTaints),
+		Lint:                lint.Tolerations(in.WorkloadTolerations, nodeTaints, in.Lint),
+		UnusedTaints:        lint.UnusedTaints(nodeTaints, clusterTolerations),
+		OrphanedTolerations: lint.OrphanedTolerations(in.WorkloadTolerations, nodeTaints),
+		Coverage:            make([]coverage.Report, 0, len(in.DaemonSets)),
+	}
+
+	for _, workload := range resources.TolerationsResults(in.WorkloadTolerations) {
+		row := Row{TolerationsResult: workload, Tolerated: make([]bool, 0, len(r.TaintSets))}
+		for _, set := range r.TaintSets {
+			row.Tolerated = append(row.Tolerated, resources.Tolerates(workload.Tolerations, set.Taints))
+		}
+		r.Matrix = append(r.Matrix, row)
+	}
+
+	for _, ds := range in.DaemonSets {
+		ref := resources.ResourceReference{Namespace: ds.Namespace, Name: ds.Name, Kind: "DaemonSet"}
+		r.Coverage = append(r.Coverage, coverage.Compute(ds, in.Nodes, in.DaemonSetPods[ref], in.DaemonSetOptions))
+	}
+	return r
+}
+
+// WriteHTML writes the report as a single self-contained HTML page
+func (r *Report) WriteHTML(w io.Writer) error {
+	t, err := template.New("report").Funcs(funcs).Parse(reportTemplate)
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, r)
+}
@@ -0,0 +1,115 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNew(t *testing.T) {
+	db := v1.Taint{Key: "app", Value: "db", Effect: v1.TaintEffectNoSchedule}
+	in := Input{
+		Source: "prod",
+		Nodes: map[resources.ResourceReference]v1.Node{
+			{Name: "web-1", Kind: "Node"}: _node("web-1"),
+			{Name: "db-1", Kind: "Node"}:  _node("db-1", db),
+		},
+		WorkloadTolerations: map[resources.ResourceReference][]v1.Toleration{
+			{Namespace: "default", Name: "postgres", Kind: "StatefulSet"}: {
+				{Key: "app", Operator: v1.TolerationOpEqual, Value: "db", Effect: v1.TaintEffectNoSchedule},
+				{Key: "app", Operator: v1.TolerationOpEqual, Value: "cache", Effect: v1.TaintEffectNoSchedule},
+				{Key: "app", Operator: v1.TolerationOpEqual, Value: "queue", Effect: v1.TaintEffectNoSchedule},
+			},
+			{Namespace: "default", Name: "nginx", Kind: "Deployment"}: {},
+		},
+		DaemonSets: []appsv1.DaemonSet{
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "logging", Name: "fluent-bit"}},
+		},
+	}
+
+	r := New(in)
+	assert.Equal(t, 2, r.Nodes)
+	assert.Len(t, r.TaintSets, 2)
+	assert.Equal(t, []Row{
+		{TolerationsResult: resources.TolerationsResult{ResourceReference: resources.ResourceReference{Namespace: "default", Name: "nginx", Kind: "Deployment"}, Tolerations: []v1.Toleration{}}, Tolerated: []bool{true, false}},
+		{TolerationsResult: resources.TolerationsResult{ResourceReference: resources.ResourceReference{Namespace: "default", Name: "postgres", Kind: "StatefulSet"}, Tolerations: in.WorkloadTolerations[resources.ResourceReference{Namespace: "default", Name: "postgres", Kind: "StatefulSet"}]}, Tolerated: []bool{true, true}},
+	}, r.Matrix)
+	assert.Len(t, r.Coverage, 1)
+	assert.Len(t, r.Coverage[0].Uncovered, 1)
+
+	var buf bytes.Buffer
+	assert.NoError(t, r.WriteHTML(&buf))
+	html := buf.String()
+	for _, expected := range []string{
+		"<title>ttsum report - prod</title>",
+		"app=db:NoSchedule",
+		"postgres</td>",
+		"<td>fluent-bit</td>",
+		"app=db:NoSchedule: db-1",
+		"<td class=\"no\">no</td>",
+		"<td>postgres</td><td>2</td><td>1</td>",
+		"<script>",
+	} {
+		assert.Contains(t, html, expected)
+	}
+}
+
+func TestNewClusterTolerations(t *testing.T) {
+	db := v1.Taint{Key: "app", Value: "db", Effect: v1.TaintEffectNoSchedule}
+	postgres := resources.ResourceReference{Namespace: "data", Name: "postgres", Kind: "StatefulSet"}
+	nginx := resources.ResourceReference{Namespace: "default", Name: "nginx", Kind: "Deployment"}
+
+	tests := []struct {
+		Description        string
+		ClusterTolerations map[resources.ResourceReference][]v1.Toleration
+		ExpectedUnused     int
+	}{
+		{
+			Description:    "taint tolerated outside of the selected namespace is unused without cluster tolerations",
+			ExpectedUnused: 1,
+		},
+		{
+			Description: "taint tolerated outside of the selected namespace is used",
+			ClusterTolerations: map[resources.ResourceReference][]v1.Toleration{
+				postgres: {{Key: "app", Operator: v1.TolerationOpEqual, Value: "db", Effect: v1.TaintEffectNoSchedule}},
+				nginx:    {},
+			},
+			ExpectedUnused: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		r := New(Input{
+			Nodes:               map[resources.ResourceReference]v1.Node{{Name: "db-1", Kind: "Node"}: _node("db-1", db)},
+			WorkloadTolerations: map[resources.ResourceReference][]v1.Toleration{nginx: {}},
+			ClusterTolerations:  test.ClusterTolerations,
+		})
+		assert.Len(t, r.UnusedTaints, test.ExpectedUnused)
+		assert.Len(t, r.Matrix, 1)
+	}
+}
+
+func _node(name string, taints ...v1.Taint) v1.Node {
+	return v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1.NodeSpec{Taints: taints},
+	}
+}
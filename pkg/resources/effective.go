@@ -0,0 +1,290 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/eytan-avisror/ttsum/pkg/tolerations"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// DefaultTolerationsAnnotation holds the tolerations the PodTolerationRestriction admission plugin
+	// merges into pods of a namespace
+	DefaultTolerationsAnnotation = "scheduler.alpha.kubernetes.io/defaultTolerations"
+	// TolerationsWhitelistAnnotation holds the only tolerations the PodTolerationRestriction admission
+	// plugin allows in pods of a namespace
+	TolerationsWhitelistAnnotation = "scheduler.alpha.kubernetes.io/tolerationsWhitelist"
+)
+
+// Source describes where an effective toleration comes from
+type Source string
+
+const (
+	SourceTemplate                 Source = "template"
+	SourceNamespaceDefault         Source = "namespace-default"
+	SourceDefaultTolerationSeconds Source = "default-toleration-seconds"
+	SourceDaemonSetController      Source = "daemonset-controller"
+	SourceRuntimeClass             Source = "runtimeclass"
+)
+
+// DefaultTolerationSeconds is the toleration period the DefaultTolerationSeconds admission plugin
+// sets on not-ready and unreachable tolerations by default
+const DefaultTolerationSeconds int64 = 300
+
+// Implicit returns true if tolerations from the source are added without being declared
+func (s Source) Implicit() bool {
+	return s == SourceDefaultTolerationSeconds || s == SourceDaemonSetController
+}
+
+// EffectiveToleration is a toleration a pod will have once admitted, along with its source
+type EffectiveToleration struct {
+	v1.Toleration
+	Source Source `json:"source"`
+}
+
+// EffectiveTolerationsResult holds the effective tolerations of a resource, and the tolerations
+// the namespace whitelist would reject
+type EffectiveTolerationsResult struct {
+	ResourceReference
+	Tolerations []EffectiveToleration `json:"tolerations"`
+	Rejected    []v1.Toleration       `json:"rejected,omitempty"`
+}
+
+// NamespaceTolerations are the PodTolerationRestriction annotations of a namespace
+type NamespaceTolerations struct {
+	Defaults  []v1.Toleration `json:"defaults,omitempty"`
+	Whitelist []v1.Toleration `json:"whitelist,omitempty"`
+}
+
+// EffectiveOptions selects which admission behaviour is overlaid on template tolerations
+type EffectiveOptions struct {
+	// Namespaces are the namespace annotations keyed by namespace name, namespaces are ignored when nil
+	Namespaces map[string]NamespaceTolerations
+	// Implicit adds the tolerations of the DefaultTolerationSeconds admission plugin, and of the
+	// DaemonSet controller to DaemonSets
+	Implicit bool
+	// RuntimeClasses are the RuntimeClass scheduling tolerations keyed by RuntimeClass name
+	RuntimeClasses map[string][]v1.Toleration
+	// PodSpecs are the pod specs of the resources, used to resolve tolerations depending on the spec
+	PodSpecs map[ResourceReference]v1.PodSpec
+}
+
+// ListEffectiveOptions lists the namespace and RuntimeClass tolerations for EffectiveTolerations of
+// the resources of specs, along with their implicit tolerations
+func ListEffectiveOptions(client dynamic.Interface, specs map[ResourceReference]v1.PodSpec) (EffectiveOptions, error) {
+	opts := EffectiveOptions{
+		Implicit: true,
+		PodSpecs: specs,
+	}
+
+	var err error
+	if opts.Namespaces, err = ListNamespaceTolerations(client); err != nil {
+		return opts, err
+	}
+	if opts.RuntimeClasses, err = ListRuntimeClassTolerations(client, specs); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// ListNamespaceTolerations reads the PodTolerationRestriction annotations of all namespaces
+func ListNamespaceTolerations(client dynamic.Interface) (map[string]NamespaceTolerations, error) {
+	var namespaces = make(map[string]NamespaceTolerations)
+
+	r, err := client.Resource(NamespaceGVR).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return namespaces, err
+	}
+
+	for _, ns := range r.Items {
+		var (
+			annotations = ns.GetAnnotations()
+			nsTols      NamespaceTolerations
+		)
+
+		if raw, ok := annotations[DefaultTolerationsAnnotation]; ok {
+			if err := json.Unmarshal([]byte(raw), &nsTols.Defaults); err != nil {
+				return namespaces, errors.Wrapf(err, "invalid %v annotation on namespace %v", DefaultTolerationsAnnotation, ns.GetName())
+			}
+		}
+		if raw, ok := annotations[TolerationsWhitelistAnnotation]; ok {
+			if err := json.Unmarshal([]byte(raw), &nsTols.Whitelist); err != nil {
+				return namespaces, errors.Wrapf(err, "invalid %v annotation on namespace %v", TolerationsWhitelistAnnotation, ns.GetName())
+			}
+		}
+		defaultOperators(nsTols.Defaults)
+		defaultOperators(nsTols.Whitelist)
+		namespaces[ns.GetName()] = nsTols
+	}
+	return namespaces, nil
+}
+
+// EffectiveTolerations overlays admission behaviour on the template tolerations of each resource
+func EffectiveTolerations(objs map[ResourceReference][]v1.Toleration, opts EffectiveOptions) map[ResourceReference]EffectiveTolerationsResult {
+	results := make(map[ResourceReference]EffectiveTolerationsResult)
+
+	for ref, tols := range objs {
+		result := EffectiveTolerationsResult{
+			ResourceReference: ref,
+			Tolerations:       withSource(tols, SourceTemplate),
+		}
+
+		if opts.Implicit {
+			if ref.Kind == "DaemonSet" {
+				result.Tolerations = addOrUpdateEffective(result.Tolerations, daemonSetTolerations(opts.PodSpecs[ref]))
+			}
+			result.Tolerations = append(result.Tolerations, defaultSecondsTolerations(result.Tolerations)...)
+		}
+
+		nsTols, ok := opts.Namespaces[ref.Namespace]
+		if ok {
+			result.Tolerations = mergeEffective(result.Tolerations, withSource(nsTols.Defaults, SourceNamespaceDefault))
+		}
+
+		// PodTolerationRestriction admits pods before the RuntimeClass plugin adds its tolerations,
+		// which are never checked against the whitelist
+		if len(nsTols.Whitelist) > 0 {
+			result.Rejected = tolerations.NotWhitelisted(PlainTolerations(result.Tolerations), nsTols.Whitelist)
+		}
+
+		if rcTols := RuntimeClassTolerationsFor(opts.PodSpecs[ref], opts.RuntimeClasses); len(rcTols) > 0 {
+			result.Tolerations = mergeEffective(result.Tolerations, withSource(rcTols, SourceRuntimeClass))
+		}
+		results[ref] = result
+	}
+	return results
+}
+
+// daemonSetTolerations are the tolerations the DaemonSet controller adds to its pods
+func daemonSetTolerations(spec v1.PodSpec) []EffectiveToleration {
+	tols := []v1.Toleration{
+		{Key: v1.TaintNodeNotReady, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute},
+		{Key: v1.TaintNodeUnreachable, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute},
+		{Key: v1.TaintNodeDiskPressure, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+		{Key: v1.TaintNodeMemoryPressure, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+		{Key: v1.TaintNodePIDPressure, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+		{Key: v1.TaintNodeUnschedulable, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+	}
+	if spec.HostNetwork {
+		tols = append(tols, v1.Toleration{Key: v1.TaintNodeNetworkUnavailable, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule})
+	}
+	return withSource(tols, SourceDaemonSetController)
+}
+
+// defaultSecondsTolerations are the tolerations the DefaultTolerationSeconds admission plugin adds
+// to pods which do not already tolerate not-ready or unreachable NoExecute taints
+func defaultSecondsTolerations(effective []EffectiveToleration) []EffectiveToleration {
+	var (
+		seconds          = DefaultTolerationSeconds
+		tolerateNotReady bool
+		tolerateUnreach  bool
+		added            = make([]v1.Toleration, 0)
+	)
+
+	for _, t := range effective {
+		if t.Effect != v1.TaintEffectNoExecute && t.Effect != "" {
+			continue
+		}
+		if t.Key == v1.TaintNodeNotReady || t.Key == "" {
+			tolerateNotReady = true
+		}
+		if t.Key == v1.TaintNodeUnreachable || t.Key == "" {
+			tolerateUnreach = true
+		}
+	}
+
+	if !tolerateNotReady {
+		added = append(added, v1.Toleration{Key: v1.TaintNodeNotReady, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute, TolerationSeconds: &seconds})
+	}
+	if !tolerateUnreach {
+		added = append(added, v1.Toleration{Key: v1.TaintNodeUnreachable, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute, TolerationSeconds: &seconds})
+	}
+	return withSource(added, SourceDefaultTolerationSeconds)
+}
+
+// addOrUpdateEffective replaces tolerations matching an update and appends the others, the way
+// controllers add tolerations to pod specs
+func addOrUpdateEffective(effective, updates []EffectiveToleration) []EffectiveToleration {
+	for _, u := range updates {
+		updated := false
+		for i := range effective {
+			if !effective[i].MatchToleration(&u.Toleration) {
+				continue
+			}
+			if !equalTolerationSeconds(effective[i].TolerationSeconds, u.TolerationSeconds) {
+				effective[i] = u
+			}
+			updated = true
+		}
+		if !updated {
+			effective = append(effective, u)
+		}
+	}
+	return effective
+}
+
+func equalTolerationSeconds(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// PlainTolerations drops the source of effective tolerations
+func PlainTolerations(effective []EffectiveToleration) []v1.Toleration {
+	tols := make([]v1.Toleration, 0, len(effective))
+	for _, t := range effective {
+		tols = append(tols, t.Toleration)
+	}
+	return tols
+}
+
+// mergeEffective merges tolerations like tolerations.Merge while keeping track of their source
+func mergeEffective(first, second []EffectiveToleration) []EffectiveToleration {
+	var (
+		all    = append(append(make([]EffectiveToleration, 0, len(first)+len(second)), first...), second...)
+		merged = make([]EffectiveToleration, 0, len(all))
+	)
+
+	for _, t := range tolerations.Merge(PlainTolerations(first), PlainTolerations(second)) {
+		for _, e := range all {
+			if e.MatchToleration(&t) {
+				merged = append(merged, EffectiveToleration{Toleration: t, Source: e.Source})
+				break
+			}
+		}
+	}
+	return merged
+}
+
+func defaultOperators(tols []v1.Toleration) {
+	for i := range tols {
+		if tols[i].Operator == "" {
+			tols[i].Operator = v1.TolerationOpEqual
+		}
+	}
+}
+
+func withSource(tols []v1.Toleration, source Source) []EffectiveToleration {
+	effective := make([]EffectiveToleration, 0, len(tols))
+	for _, t := range tols {
+		effective = append(effective, EffectiveToleration{Toleration: t, Source: source})
+	}
+	return effective
+}
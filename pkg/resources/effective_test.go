@@ -0,0 +1,148 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestEffectiveTolerations(t *testing.T) {
+	client := _fakeClient()
+	namespaces := []*unstructured.Unstructured{
+		_unstructuredNamespace("restricted", map[string]string{
+			DefaultTolerationsAnnotation:   `[{"key": "dedicated", "operator": "Equal", "value": "db", "effect": "NoSchedule"}]`,
+			TolerationsWhitelistAnnotation: `[{"key": "dedicated", "operator": "Exists"}]`,
+		}),
+		_unstructuredNamespace("open", nil),
+	}
+	for _, ns := range namespaces {
+		_, err := client.Resource(NamespaceGVR).Create(context.Background(), ns, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	namespaceTolerations, err := ListNamespaceTolerations(client)
+	assert.NoError(t, err)
+
+	objs := map[ResourceReference][]v1.Toleration{
+		_resourceReference("restricted", "mysql", "Deployment"): {_toleration("Equal", "app", "web", "NoSchedule")},
+		_resourceReference("open", "nginx", "Deployment"):       {_toleration("Equal", "app", "web", "NoSchedule")},
+	}
+
+	effective := EffectiveTolerations(objs, EffectiveOptions{Namespaces: namespaceTolerations})
+
+	assert.Equal(t, EffectiveTolerationsResult{
+		ResourceReference: _resourceReference("restricted", "mysql", "Deployment"),
+		Tolerations: []EffectiveToleration{
+			{Toleration: _toleration("Equal", "app", "web", "NoSchedule"), Source: SourceTemplate},
+			{Toleration: _toleration("Equal", "dedicated", "db", "NoSchedule"), Source: SourceNamespaceDefault},
+		},
+		Rejected: []v1.Toleration{_toleration("Equal", "app", "web", "NoSchedule")},
+	}, effective[_resourceReference("restricted", "mysql", "Deployment")])
+
+	assert.Equal(t, EffectiveTolerationsResult{
+		ResourceReference: _resourceReference("open", "nginx", "Deployment"),
+		Tolerations: []EffectiveToleration{
+			{Toleration: _toleration("Equal", "app", "web", "NoSchedule"), Source: SourceTemplate},
+		},
+	}, effective[_resourceReference("open", "nginx", "Deployment")])
+}
+
+func TestEffectiveTolerationsRuntimeClassNotRejected(t *testing.T) {
+	var (
+		ref     = _resourceReference("restricted", "sandboxed", "Deployment")
+		gvisor  = "gvisor"
+		sandbox = _toleration("Equal", "sandbox", "gvisor", "NoSchedule")
+	)
+
+	effective := EffectiveTolerations(map[ResourceReference][]v1.Toleration{
+		ref: {_toleration("Exists", "dedicated", "", "")},
+	}, EffectiveOptions{
+		Namespaces: map[string]NamespaceTolerations{
+			"restricted": {Whitelist: []v1.Toleration{_toleration("Exists", "dedicated", "", "")}},
+		},
+		RuntimeClasses: map[string][]v1.Toleration{gvisor: {sandbox}},
+		PodSpecs:       map[ResourceReference]v1.PodSpec{ref: {RuntimeClassName: &gvisor}},
+	})
+
+	assert.Equal(t, []EffectiveToleration{
+		{Toleration: _toleration("Exists", "dedicated", "", ""), Source: SourceTemplate},
+		{Toleration: sandbox, Source: SourceRuntimeClass},
+	}, effective[ref].Tolerations)
+	assert.Empty(t, effective[ref].Rejected)
+}
+
+func _unstructuredNamespace(name string, annotations map[string]string) *unstructured.Unstructured {
+	ns := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Namespace",
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+		},
+	}
+	ns.SetAnnotations(annotations)
+	return ns
+}
+
+func TestImplicitTolerations(t *testing.T) {
+	var seconds = DefaultTolerationSeconds
+
+	notReady := v1.Toleration{Key: v1.TaintNodeNotReady, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute}
+	unreachable := v1.Toleration{Key: v1.TaintNodeUnreachable, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute}
+	notReadySeconds := notReady
+	notReadySeconds.TolerationSeconds = &seconds
+	unreachableSeconds := unreachable
+	unreachableSeconds.TolerationSeconds = &seconds
+
+	daemonSet := _resourceReference("kube-system", "kube-proxy", "DaemonSet")
+	objs := map[ResourceReference][]v1.Toleration{
+		_resourceReference("default", "nginx", "Deployment"): {_toleration("Equal", "app", "web", "NoSchedule")},
+		_resourceReference("default", "mysql", "Deployment"): {notReady},
+		daemonSet: {unreachableSeconds},
+	}
+
+	effective := EffectiveTolerations(objs, EffectiveOptions{
+		Implicit: true,
+		PodSpecs: map[ResourceReference]v1.PodSpec{daemonSet: {HostNetwork: true}},
+	})
+
+	t.Log("Deployments get not-ready and unreachable tolerations with default seconds")
+	assert.Equal(t, []EffectiveToleration{
+		{Toleration: _toleration("Equal", "app", "web", "NoSchedule"), Source: SourceTemplate},
+		{Toleration: notReadySeconds, Source: SourceDefaultTolerationSeconds},
+		{Toleration: unreachableSeconds, Source: SourceDefaultTolerationSeconds},
+	}, effective[_resourceReference("default", "nginx", "Deployment")].Tolerations)
+
+	t.Log("Declared not-ready tolerations are not overridden by default seconds")
+	assert.Equal(t, []EffectiveToleration{
+		{Toleration: notReady, Source: SourceTemplate},
+		{Toleration: unreachableSeconds, Source: SourceDefaultTolerationSeconds},
+	}, effective[_resourceReference("default", "mysql", "Deployment")].Tolerations)
+
+	t.Log("DaemonSets get controller tolerations which replace matching declared tolerations")
+	tols := effective[daemonSet].Tolerations
+	assert.Equal(t, EffectiveToleration{Toleration: unreachable, Source: SourceDaemonSetController}, tols[0])
+	assert.Equal(t, EffectiveToleration{Toleration: notReady, Source: SourceDaemonSetController}, tols[1])
+	assert.Len(t, tols, 7)
+	assert.Equal(t, v1.TaintNodeNetworkUnavailable, tols[6].Key)
+	for _, tol := range tols {
+		assert.True(t, tol.Source.Implicit())
+	}
+}
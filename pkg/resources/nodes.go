@@ -0,0 +1,113 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+)
+
+// ConditionTaint is a taint the node lifecycle controller adds while a node condition has a status
+type ConditionTaint struct {
+	Condition v1.NodeConditionType
+	Status    v1.ConditionStatus
+	Key       string
+}
+
+// ConditionTaints are the taints which mirror node conditions
+var ConditionTaints = []ConditionTaint{
+	{Condition: v1.NodeReady, Status: v1.ConditionFalse, Key: v1.TaintNodeNotReady},
+	{Condition: v1.NodeReady, Status: v1.ConditionUnknown, Key: v1.TaintNodeUnreachable},
+	{Condition: v1.NodeMemoryPressure, Status: v1.ConditionTrue, Key: v1.TaintNodeMemoryPressure},
+	{Condition: v1.NodeDiskPressure, Status: v1.ConditionTrue, Key: v1.TaintNodeDiskPressure},
+	{Condition: v1.NodePIDPressure, Status: v1.ConditionTrue, Key: v1.TaintNodePIDPressure},
+	{Condition: v1.NodeNetworkUnavailable, Status: v1.ConditionTrue, Key: v1.TaintNodeNetworkUnavailable},
+}
+
+// ListNodes lists nodes
+func ListNodes(client dynamic.Interface) (map[ResourceReference]v1.Node, error) {
+	var nodes = make(map[ResourceReference]v1.Node)
+
+	r, err := client.Resource(NodeGVR).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nodes, err
+	}
+
+	for i := range r.Items {
+		var node v1.Node
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(r.Items[i].Object, &node); err != nil {
+			return nodes, err
+		}
+		nodes[ReferenceFor(&r.Items[i])] = node
+	}
+	return nodes, nil
+}
+
+// GetNode gets a single node, the error is NotFound when it does not exist
+func GetNode(client dynamic.Interface, name string) (v1.Node, error) {
+	var node v1.Node
+
+	obj, err := client.Resource(NodeGVR).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return node, err
+	}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &node)
+	return node, err
+}
+
+// ConditionStatus returns the status of a node condition, conditions which are not reported are Unknown
+func ConditionStatus(node v1.Node, condition v1.NodeConditionType) v1.ConditionStatus {
+	for _, c := range node.Status.Conditions {
+		if c.Type == condition {
+			return c.Status
+		}
+	}
+	return v1.ConditionUnknown
+}
+
+// ConditionMismatches describes condition taints which are missing while their condition holds, or
+// present while it does not, including the unschedulable taint of cordoned nodes
+func ConditionMismatches(node v1.Node) []string {
+	mismatches := make([]string, 0)
+
+	for _, ct := range ConditionTaints {
+		status := ConditionStatus(node, ct.Condition)
+		mismatches = appendMismatch(mismatches, node, ct.Key, status == ct.Status, fmt.Sprintf("%v=%v", ct.Condition, status))
+	}
+	return appendMismatch(mismatches, node, v1.TaintNodeUnschedulable, node.Spec.Unschedulable, fmt.Sprintf("unschedulable=%v", node.Spec.Unschedulable))
+}
+
+func appendMismatch(mismatches []string, node v1.Node, key string, expected bool, reason string) []string {
+	tainted := hasTaintKey(node.Spec.Taints, key)
+	switch {
+	case expected && !tainted:
+		return append(mismatches, fmt.Sprintf("missing %v (%v)", key, reason))
+	case !expected && tainted:
+		return append(mismatches, fmt.Sprintf("unexpected %v (%v)", key, reason))
+	}
+	return mismatches
+}
+
+func hasTaintKey(taints []v1.Taint, key string) bool {
+	for _, t := range taints {
+		if t.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestConditionMismatches(t *testing.T) {
+	tests := []struct {
+		Description        string
+		Node               *unstructured.Unstructured
+		Conditions         map[v1.NodeConditionType]v1.ConditionStatus
+		Unschedulable      bool
+		ExpectedMismatches []string
+	}{
+		{
+			Description: "ready node without taints",
+			Node:        _unstructuredNode("ip-1-2-3-4.ec2.internal"),
+			Conditions:  map[v1.NodeConditionType]v1.ConditionStatus{v1.NodeReady: v1.ConditionTrue, v1.NodeMemoryPressure: v1.ConditionFalse},
+		},
+		{
+			Description:        "not ready node without taints",
+			Node:               _unstructuredNode("ip-1-2-3-4.ec2.internal"),
+			Conditions:         map[v1.NodeConditionType]v1.ConditionStatus{v1.NodeReady: v1.ConditionFalse},
+			ExpectedMismatches: []string{"missing node.kubernetes.io/not-ready (Ready=False)"},
+		},
+		{
+			Description:        "ready node with a stale memory pressure taint",
+			Node:               _unstructuredNode("ip-1-2-3-4.ec2.internal", _taint(v1.TaintNodeMemoryPressure, "", "NoSchedule")),
+			Conditions:         map[v1.NodeConditionType]v1.ConditionStatus{v1.NodeReady: v1.ConditionTrue, v1.NodeMemoryPressure: v1.ConditionFalse},
+			ExpectedMismatches: []string{"unexpected node.kubernetes.io/memory-pressure (MemoryPressure=False)"},
+		},
+		{
+			Description:   "cordoned node with unschedulable taint",
+			Node:          _unstructuredNode("ip-1-2-3-4.ec2.internal", _taint(v1.TaintNodeUnschedulable, "", "NoSchedule")),
+			Conditions:    map[v1.NodeConditionType]v1.ConditionStatus{v1.NodeReady: v1.ConditionTrue},
+			Unschedulable: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		client := _fakeClient()
+
+		conditions := make([]interface{}, 0)
+		for c, status := range test.Conditions {
+			conditions = append(conditions, map[string]interface{}{"type": string(c), "status": string(status)})
+		}
+		unstructured.SetNestedSlice(test.Node.Object, conditions, "status", "conditions")
+		unstructured.SetNestedField(test.Node.Object, test.Unschedulable, "spec", "unschedulable")
+
+		_, err := client.Resource(NodeGVR).Create(context.Background(), test.Node, metav1.CreateOptions{})
+		assert.NoError(t, err)
+
+		nodes, err := ListNodes(client)
+		assert.NoError(t, err)
+
+		node := nodes[_resourceReference("", "ip-1-2-3-4.ec2.internal", "Node")]
+		expected := test.ExpectedMismatches
+		if expected == nil {
+			expected = []string{}
+		}
+		assert.Equal(t, expected, ConditionMismatches(node))
+	}
+}
+
+func TestGetNode(t *testing.T) {
+	client := _fakeClient()
+	_, err := client.Resource(NodeGVR).Create(context.Background(), _unstructuredNode("db-1", v1.Taint{Key: "app", Value: "db", Effect: v1.TaintEffectNoSchedule}), metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	node, err := GetNode(client, "db-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "db-1", node.Name)
+	assert.Equal(t, []v1.Taint{{Key: "app", Value: "db", Effect: v1.TaintEffectNoSchedule}}, node.Spec.Taints)
+
+	_, err = GetNode(client, "db-2")
+	assert.True(t, apierrors.IsNotFound(err))
+}
@@ -17,6 +17,8 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/eytan-avisror/ttsum/pkg/tolerations"
+	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -34,6 +36,10 @@ var (
 		Resource: "nodes",
 		Version:  "v1",
 	}
+	NamespaceGVR = schema.GroupVersionResource{
+		Resource: "namespaces",
+		Version:  "v1",
+	}
 )
 
 func Parse(apiVersion, kind string) schema.GroupVersionResource {
@@ -52,50 +58,96 @@ func Parse(apiVersion, kind string) schema.GroupVersionResource {
 	return gvr
 }
 
+// ParseReference parses a reference in the format kind/namespace/name, or kind/name for cluster scoped resources
+func ParseReference(s string) (ResourceReference, error) {
+	var ref ResourceReference
+
+	spl := strings.Split(s, "/")
+	switch len(spl) {
+	case 2:
+		ref.Kind = spl[0]
+		ref.Name = spl[1]
+	case 3:
+		ref.Kind = spl[0]
+		ref.Namespace = spl[1]
+		ref.Name = spl[2]
+	default:
+		return ref, errors.Errorf("invalid reference: %v, must be in format kind/namespace/name", s)
+	}
+
+	if ref.Kind == "" || ref.Name == "" {
+		return ref, errors.Errorf("invalid reference: %v, must be in format kind/namespace/name", s)
+	}
+	return ref, nil
+}
+
+// Matches returns true if ref refers to the same resource as r, kinds are compared case insensitively
+// and singular or plural resource names are accepted
+func (r ResourceReference) Matches(ref ResourceReference) bool {
+	if r.Namespace != ref.Namespace || r.Name != ref.Name {
+		return false
+	}
+	kind := strings.ToLower(ref.Kind)
+	return strings.EqualFold(r.Kind, kind) || strings.EqualFold(r.Kind+"s", kind)
+}
+
 type ResourceReference struct {
-	Namespace string
-	Name      string
-	Kind      string
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Kind      string `json:"kind,omitempty"`
 }
 
+// ListResourceTolerations lists the tolerations of a resource, merged with the scheduling
+// tolerations of the RuntimeClass its pod template references
 func ListResourceTolerations(client dynamic.Interface, gvr schema.GroupVersionResource, namespace string) (map[ResourceReference][]v1.Toleration, error) {
-	var tolerations = make(map[ResourceReference][]v1.Toleration)
+	resourceTolerations, specs, err := ListResourceTemplates(client, gvr, namespace)
+	if err != nil {
+		return resourceTolerations, err
+	}
+
+	runtimeClasses, err := ListRuntimeClassTolerations(client, specs)
+	if err != nil {
+		return resourceTolerations, err
+	}
+	return MergeRuntimeClassTolerations(resourceTolerations, specs, runtimeClasses), nil
+}
+
+// ListResourceTemplates lists the tolerations declared in the pod template of a resource, along
+// with the pod template spec. Objects whose template is not a pod spec have no spec, since
+// custom resources may keep tolerations under any parent
+func ListResourceTemplates(client dynamic.Interface, gvr schema.GroupVersionResource, namespace string) (map[ResourceReference][]v1.Toleration, map[ResourceReference]v1.PodSpec, error) {
+	var (
+		tolerations = make(map[ResourceReference][]v1.Toleration)
+		specs       = make(map[ResourceReference]v1.PodSpec)
+	)
 
 	r, err := client.Resource(gvr).Namespace(namespace).List(context.Background(), metav1.ListOptions{})
 	if err != nil {
-		return tolerations, err
+		return tolerations, specs, err
 	}
 
-	for _, resource := range r.Items {
-		ref := ResourceReference{
-			Namespace: resource.GetNamespace(),
-			Name:      resource.GetName(),
-			Kind:      resource.GetKind(),
-		}
-		tolerations[ref] = make([]v1.Toleration, 0)
-		res, ok, err := unstructured.NestedSlice(resource.Object, TolerationPath...)
-		if !ok {
-			continue
-		}
+	for i := range r.Items {
+		ref := ReferenceFor(&r.Items[i])
+		tolerations[ref], err = TolerationsFromObject(&r.Items[i], TolerationPathFor(gvr))
 		if err != nil {
-			return tolerations, err
+			return tolerations, specs, err
 		}
+		if spec, err := PodSpecFromObject(&r.Items[i], PodSpecPathFor(gvr)); err == nil {
+			specs[ref] = spec
+		}
+	}
+	return tolerations, specs, nil
+}
 
-		for _, obj := range res {
-			var toleration v1.Toleration
-			convert := obj.(map[string]interface{})
-			err := runtime.DefaultUnstructuredConverter.FromUnstructured(convert, &toleration)
-			if err != nil {
-				return tolerations, err
-			}
-
-			if toleration.Operator == "" {
-				toleration.Operator = v1.TolerationOpEqual
-			}
-			tolerations[ref] = append(tolerations[ref], toleration)
+// MergeRuntimeClassTolerations merges the scheduling tolerations of the RuntimeClass each pod spec
+// references into the tolerations of its resource
+func MergeRuntimeClassTolerations(resourceTolerations map[ResourceReference][]v1.Toleration, specs map[ResourceReference]v1.PodSpec, runtimeClasses map[string][]v1.Toleration) map[ResourceReference][]v1.Toleration {
+	for ref, tols := range resourceTolerations {
+		if rcTols := RuntimeClassTolerationsFor(specs[ref], runtimeClasses); len(rcTols) > 0 {
+			resourceTolerations[ref] = tolerations.Merge(tols, rcTols)
 		}
 	}
-	return tolerations, nil
+	return resourceTolerations
 }
 
 func ListNodeTaints(client dynamic.Interface) (map[ResourceReference][]v1.Taint, error) {
@@ -106,29 +158,71 @@ func ListNodeTaints(client dynamic.Interface) (map[ResourceReference][]v1.Taint,
 		return taints, err
 	}
 
-	for _, resource := range r.Items {
-		ref := ResourceReference{
-			Name: resource.GetName(),
-			Kind: resource.GetKind(),
-		}
-		taints[ref] = make([]v1.Taint, 0)
-		res, ok, err := unstructured.NestedSlice(resource.Object, TaintPath...)
-		if !ok {
-			continue
-		}
+	for i := range r.Items {
+		ref := ReferenceFor(&r.Items[i])
+		taints[ref], err = TaintsFromObject(&r.Items[i])
 		if err != nil {
 			return taints, err
 		}
+	}
+	return taints, nil
+}
 
-		for _, obj := range res {
-			var taint v1.Taint
-			convert := obj.(map[string]interface{})
-			err := runtime.DefaultUnstructuredConverter.FromUnstructured(convert, &taint)
-			if err != nil {
-				return taints, err
-			}
-			taints[ref] = append(taints[ref], taint)
+// ReferenceFor returns the reference of an object
+func ReferenceFor(obj *unstructured.Unstructured) ResourceReference {
+	return ResourceReference{
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Kind:      obj.GetKind(),
+	}
+}
+
+// TolerationsFromObject converts the tolerations found at path, operators default to Equal
+func TolerationsFromObject(obj *unstructured.Unstructured, path []string) ([]v1.Toleration, error) {
+	tolerations := make([]v1.Toleration, 0)
+	res, ok, err := unstructured.NestedSlice(obj.Object, path...)
+	if !ok {
+		return tolerations, nil
+	}
+	if err != nil {
+		return tolerations, err
+	}
+
+	for _, o := range res {
+		var toleration v1.Toleration
+		convert := o.(map[string]interface{})
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(convert, &toleration)
+		if err != nil {
+			return tolerations, err
+		}
+
+		if toleration.Operator == "" {
+			toleration.Operator = v1.TolerationOpEqual
+		}
+		tolerations = append(tolerations, toleration)
+	}
+	return tolerations, nil
+}
+
+// TaintsFromObject converts the taints of a node
+func TaintsFromObject(obj *unstructured.Unstructured) ([]v1.Taint, error) {
+	taints := make([]v1.Taint, 0)
+	res, ok, err := unstructured.NestedSlice(obj.Object, TaintPath...)
+	if !ok {
+		return taints, nil
+	}
+	if err != nil {
+		return taints, err
+	}
+
+	for _, o := range res {
+		var taint v1.Taint
+		convert := o.(map[string]interface{})
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(convert, &taint)
+		if err != nil {
+			return taints, err
 		}
+		taints = append(taints, taint)
 	}
 	return taints, nil
 }
@@ -159,6 +253,21 @@ func FilterTolerations(objs map[ResourceReference][]v1.Toleration, matchTolerati
 	return filteredMap
 }
 
+// FilterNamespace returns the resources in namespace, or all resources when namespace is empty
+func FilterNamespace(objs map[ResourceReference][]v1.Toleration, namespace string) map[ResourceReference][]v1.Toleration {
+	if namespace == "" {
+		return objs
+	}
+
+	filteredMap := make(map[ResourceReference][]v1.Toleration)
+	for res, tols := range objs {
+		if res.Namespace == namespace {
+			filteredMap[res] = tols
+		}
+	}
+	return filteredMap
+}
+
 func FilterTaints(objs map[ResourceReference][]v1.Taint, matchTaint v1.Taint, condition bool) map[ResourceReference][]v1.Taint {
 	filteredMap := make(map[ResourceReference][]v1.Taint)
 
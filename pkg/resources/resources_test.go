@@ -20,12 +20,14 @@ import (
 	v1 "k8s.io/api/core/v1"
 
 	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	fake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestParse(t *testing.T) {
@@ -130,6 +132,170 @@ func TestListResourceTolerations(t *testing.T) {
 	}
 }
 
+func TestListResourceTolerationsRuntimeClass(t *testing.T) {
+	client := _fakeClient()
+
+	gvisor := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "node.k8s.io/v1",
+			"kind":       "RuntimeClass",
+			"metadata": map[string]interface{}{
+				"name": "gvisor",
+			},
+			"handler": "runsc",
+			"scheduling": map[string]interface{}{
+				"tolerations": []interface{}{
+					map[string]interface{}{"key": "sandbox", "operator": "Equal", "value": "gvisor", "effect": "NoSchedule"},
+				},
+			},
+		},
+	}
+	_, err := client.Resource(RuntimeClassGVR).Create(context.Background(), gvisor, metav1.CreateOptions{})
+	assert.NoError(t, err)
+
+	sandboxed := _unstructuredDeployment("default", "sandboxed", _toleration("Equal", "app", "web", "NoSchedule"))
+	unstructured.SetNestedField(sandboxed.Object, "gvisor", "spec", "template", "spec", "runtimeClassName")
+	for _, deployment := range []*unstructured.Unstructured{sandboxed, _unstructuredDeployment("default", "plain")} {
+		_, err := client.Resource(DeploymentGVR).Namespace("default").Create(context.Background(), deployment, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	resourceMap, err := ListResourceTolerations(client, DeploymentGVR, "default")
+	assert.NoError(t, err)
+	assert.Equal(t, map[ResourceReference][]v1.Toleration{
+		_resourceReference("default", "sandboxed", "Deployment"): {
+			_toleration("Equal", "app", "web", "NoSchedule"),
+			_toleration("Equal", "sandbox", "gvisor", "NoSchedule"),
+		},
+		_resourceReference("default", "plain", "Deployment"): {},
+	}, resourceMap)
+
+	_, specs, err := ListResourceTemplates(client, DeploymentGVR, "default")
+	assert.NoError(t, err)
+	runtimeClasses, err := ListRuntimeClassTolerations(client, specs)
+	assert.NoError(t, err)
+
+	effective := EffectiveTolerations(map[ResourceReference][]v1.Toleration{
+		_resourceReference("default", "sandboxed", "Deployment"): {_toleration("Equal", "app", "web", "NoSchedule")},
+	}, EffectiveOptions{RuntimeClasses: runtimeClasses, PodSpecs: specs})
+	assert.Equal(t, []EffectiveToleration{
+		{Toleration: _toleration("Equal", "app", "web", "NoSchedule"), Source: SourceTemplate},
+		{Toleration: _toleration("Equal", "sandbox", "gvisor", "NoSchedule"), Source: SourceRuntimeClass},
+	}, effective[_resourceReference("default", "sandboxed", "Deployment")].Tolerations)
+}
+
+func TestListWorkloadTolerationsRuntimeClass(t *testing.T) {
+	gvisor := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "node.k8s.io/v1",
+			"kind":       "RuntimeClass",
+			"metadata": map[string]interface{}{
+				"name": "gvisor",
+			},
+			"handler": "runsc",
+			"scheduling": map[string]interface{}{
+				"tolerations": []interface{}{
+					map[string]interface{}{"key": "sandbox", "operator": "Equal", "value": "gvisor", "effect": "NoSchedule"},
+				},
+			},
+		},
+	}
+
+	sandboxed := _unstructuredDeployment("default", "sandboxed", _toleration("Equal", "app", "web", "NoSchedule"))
+	unstructured.SetNestedField(sandboxed.Object, "gvisor", "spec", "template", "spec", "runtimeClassName")
+	sandboxedDaemonSet := _unstructuredDeployment("default", "agent")
+	sandboxedDaemonSet.SetKind("DaemonSet")
+	unstructured.SetNestedField(sandboxedDaemonSet.Object, "gvisor", "spec", "template", "spec", "runtimeClassName")
+	notPodSpec := _unstructuredDeployment("default", "custom", _toleration("Equal", "app", "db", "NoSchedule"))
+	unstructured.SetNestedField(notPodSpec.Object, "not-a-list", "spec", "template", "spec", "containers")
+
+	tests := []struct {
+		Description         string
+		ListError           error
+		ExpectedResourceMap map[ResourceReference][]v1.Toleration
+	}{
+		{
+			Description: "runtimeclass tolerations are merged",
+			ExpectedResourceMap: map[ResourceReference][]v1.Toleration{
+				_resourceReference("default", "sandboxed", "Deployment"): {
+					_toleration("Equal", "app", "web", "NoSchedule"),
+					_toleration("Equal", "sandbox", "gvisor", "NoSchedule"),
+				},
+				_resourceReference("default", "custom", "Deployment"): {_toleration("Equal", "app", "db", "NoSchedule")},
+				_resourceReference("default", "agent", "DaemonSet"):   {_toleration("Equal", "sandbox", "gvisor", "NoSchedule")},
+			},
+		},
+		{
+			Description: "forbidden runtimeclasses are ignored",
+			ListError:   apierrors.NewForbidden(RuntimeClassGVR.GroupResource(), "", nil),
+			ExpectedResourceMap: map[ResourceReference][]v1.Toleration{
+				_resourceReference("default", "sandboxed", "Deployment"): {_toleration("Equal", "app", "web", "NoSchedule")},
+				_resourceReference("default", "custom", "Deployment"):    {_toleration("Equal", "app", "db", "NoSchedule")},
+				_resourceReference("default", "agent", "DaemonSet"):      {},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+			DeploymentGVR:   "DeploymentList",
+			StatefulSetGVR:  "StatefulSetList",
+			DaemonSetGVR:    "DaemonSetList",
+			JobGVR:          "JobList",
+			CronJobGVR:      "CronJobList",
+			RuntimeClassGVR: "RuntimeClassList",
+		}, gvisor)
+		for _, obj := range []*unstructured.Unstructured{sandboxed, notPodSpec} {
+			_, err := client.Resource(DeploymentGVR).Namespace("default").Create(context.Background(), obj, metav1.CreateOptions{})
+			assert.NoError(t, err)
+		}
+		_, err := client.Resource(DaemonSetGVR).Namespace("default").Create(context.Background(), sandboxedDaemonSet, metav1.CreateOptions{})
+		assert.NoError(t, err)
+
+		var lists int
+		client.PrependReactor("list", RuntimeClassGVR.Resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+			lists++
+			return test.ListError != nil, nil, test.ListError
+		})
+
+		resourceMap, err := ListWorkloadTolerations(client, "default")
+		assert.NoError(t, err)
+		assert.Equal(t, test.ExpectedResourceMap, resourceMap)
+		assert.Equal(t, 1, lists)
+	}
+}
+
+func TestFilterNamespace(t *testing.T) {
+	objs := map[ResourceReference][]v1.Toleration{
+		_resourceReference("default", "nginx", "Deployment"):  {_toleration("Equal", "app", "web", "NoSchedule")},
+		_resourceReference("data", "postgres", "StatefulSet"): {_toleration("Equal", "app", "db", "NoSchedule")},
+	}
+
+	tests := []struct {
+		Description string
+		Namespace   string
+		Expected    map[ResourceReference][]v1.Toleration
+	}{
+		{
+			Description: "all namespaces",
+			Expected:    objs,
+		},
+		{
+			Description: "single namespace",
+			Namespace:   "data",
+			Expected: map[ResourceReference][]v1.Toleration{
+				_resourceReference("data", "postgres", "StatefulSet"): {_toleration("Equal", "app", "db", "NoSchedule")},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		assert.Equal(t, test.Expected, FilterNamespace(objs, test.Namespace))
+	}
+}
+
 func TestListNodeTaints(t *testing.T) {
 	tests := []struct {
 		Description         string
@@ -252,9 +418,11 @@ func _unstructuredDeployment(namespace, name string, tolerations ...v1.Toleratio
 
 func _fakeClient() dynamic.Interface {
 	return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
-		{Version: "v1", Resource: "nodes"}:                      "NodeList",
-		{Group: "apps", Version: "v1", Resource: "deployments"}: "DeploymentList",
-		{Group: "apps", Version: "v1", Resource: "daemonsets"}:  "DaemonsetList",
+		{Version: "v1", Resource: "nodes"}:                                "NodeList",
+		{Version: "v1", Resource: "namespaces"}:                           "NamespaceList",
+		{Group: "apps", Version: "v1", Resource: "deployments"}:           "DeploymentList",
+		{Group: "apps", Version: "v1", Resource: "daemonsets"}:            "DaemonsetList",
+		{Group: "node.k8s.io", Version: "v1", Resource: "runtimeclasses"}: "RuntimeClassList",
 	})
 }
 
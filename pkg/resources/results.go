@@ -0,0 +1,103 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+type TaintsResult struct {
+	ResourceReference
+	Taints []v1.Taint `json:"taints"`
+}
+
+type TolerationsResult struct {
+	ResourceReference
+	Tolerations []v1.Toleration `json:"tolerations"`
+}
+
+// SchedulableResult describes the nodes a workload can and cannot be scheduled on
+type SchedulableResult struct {
+	ResourceReference
+	Tolerations     []v1.Toleration     `json:"tolerations"`
+	EligibleNodes   []ResourceReference `json:"eligibleNodes"`
+	IneligibleNodes []TaintsResult      `json:"ineligibleNodes"`
+}
+
+// TaintsResults flattens a taint map into results sorted by name
+func TaintsResults(objs map[ResourceReference][]v1.Taint) []TaintsResult {
+	results := make([]TaintsResult, 0, len(objs))
+	for ref, taints := range objs {
+		results = append(results, TaintsResult{
+			ResourceReference: ref,
+			Taints:            taints,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
+
+// TolerationsResults flattens a toleration map into results sorted by namespace, kind and name
+func TolerationsResults(objs map[ResourceReference][]v1.Toleration) []TolerationsResult {
+	results := make([]TolerationsResult, 0, len(objs))
+	for ref, tolerations := range objs {
+		results = append(results, TolerationsResult{
+			ResourceReference: ref,
+			Tolerations:       tolerations,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return LessReference(results[i].ResourceReference, results[j].ResourceReference)
+	})
+	return results
+}
+
+// TaintsMap is the inverse of TaintsResults
+func TaintsMap(results []TaintsResult) map[ResourceReference][]v1.Taint {
+	objs := make(map[ResourceReference][]v1.Taint)
+	for _, result := range results {
+		objs[result.ResourceReference] = result.Taints
+	}
+	return objs
+}
+
+// TolerationsMap is the inverse of TolerationsResults
+func TolerationsMap(results []TolerationsResult) map[ResourceReference][]v1.Toleration {
+	objs := make(map[ResourceReference][]v1.Toleration)
+	for _, result := range results {
+		objs[result.ResourceReference] = result.Tolerations
+	}
+	return objs
+}
+
+// SortReferences sorts references by namespace, kind and name
+func SortReferences(refs []ResourceReference) {
+	sort.Slice(refs, func(i, j int) bool {
+		return LessReference(refs[i], refs[j])
+	})
+}
+
+// LessReference orders references by namespace, kind and name
+func LessReference(a, b ResourceReference) bool {
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	if a.Kind != b.Kind {
+		return a.Kind < b.Kind
+	}
+	return a.Name < b.Name
+}
@@ -0,0 +1,72 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	RuntimeClassGVR = schema.GroupVersionResource{Group: "node.k8s.io", Version: "v1", Resource: "runtimeclasses"}
+
+	// RuntimeClassTolerationPath is the path to the tolerations a RuntimeClass adds to its pods
+	RuntimeClassTolerationPath = []string{"scheduling", "tolerations"}
+)
+
+// ListRuntimeClassTolerations returns the scheduling tolerations of the RuntimeClasses referenced by
+// pod specs keyed by RuntimeClass name, RuntimeClasses are not listed when no pod spec references one
+// and are ignored when the cluster does not serve them or they may not be listed
+func ListRuntimeClassTolerations(client dynamic.Interface, specs map[ResourceReference]v1.PodSpec) (map[string][]v1.Toleration, error) {
+	var runtimeClasses = make(map[string][]v1.Toleration)
+
+	referenced := false
+	for _, spec := range specs {
+		if spec.RuntimeClassName != nil {
+			referenced = true
+			break
+		}
+	}
+	if !referenced {
+		return runtimeClasses, nil
+	}
+
+	r, err := client.Resource(RuntimeClassGVR).List(context.Background(), metav1.ListOptions{})
+	if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+		return runtimeClasses, nil
+	}
+	if err != nil {
+		return runtimeClasses, err
+	}
+
+	for i := range r.Items {
+		runtimeClasses[r.Items[i].GetName()], err = TolerationsFromObject(&r.Items[i], RuntimeClassTolerationPath)
+		if err != nil {
+			return runtimeClasses, err
+		}
+	}
+	return runtimeClasses, nil
+}
+
+// RuntimeClassTolerationsFor returns the scheduling tolerations of the RuntimeClass a pod spec references
+func RuntimeClassTolerationsFor(spec v1.PodSpec, runtimeClasses map[string][]v1.Toleration) []v1.Toleration {
+	if spec.RuntimeClassName == nil {
+		return nil
+	}
+	return runtimeClasses[*spec.RuntimeClassName]
+}
@@ -0,0 +1,138 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// TaintSet is a group of nodes sharing the exact same taints
+type TaintSet struct {
+	Key    string              `json:"key"`
+	Taints []v1.Taint          `json:"taints"`
+	Nodes  []ResourceReference `json:"nodes"`
+}
+
+// Tolerates returns true if the tolerations tolerate every NoSchedule and NoExecute taint,
+// PreferNoSchedule taints never prevent scheduling
+func Tolerates(tolerations []v1.Toleration, taints []v1.Taint) bool {
+	return len(UntoleratedTaints(tolerations, taints)) == 0
+}
+
+// UntoleratedTaints returns the NoSchedule and NoExecute taints which are not tolerated
+func UntoleratedTaints(tolerations []v1.Toleration, taints []v1.Taint) []v1.Taint {
+	untolerated := make([]v1.Taint, 0)
+	for i := range taints {
+		if taints[i].Effect == v1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !ToleratesTaint(tolerations, &taints[i]) {
+			untolerated = append(untolerated, taints[i])
+		}
+	}
+	return untolerated
+}
+
+// ToleratesTaint returns true if any of the tolerations tolerates the taint
+func ToleratesTaint(tolerations []v1.Toleration, taint *v1.Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// EligibleNodes returns the nodes whose taints are tolerated, sorted by name
+func EligibleNodes(tolerations []v1.Toleration, nodeTaints map[ResourceReference][]v1.Taint) []ResourceReference {
+	eligible := make([]ResourceReference, 0)
+	for node, taints := range nodeTaints {
+		if Tolerates(tolerations, taints) {
+			eligible = append(eligible, node)
+		}
+	}
+	sort.Slice(eligible, func(i, j int) bool {
+		return eligible[i].Name < eligible[j].Name
+	})
+	return eligible
+}
+
+// Schedulable returns the eligible nodes of a workload, ineligible nodes hold the taints which are not tolerated
+func Schedulable(ref ResourceReference, tolerations []v1.Toleration, nodeTaints map[ResourceReference][]v1.Taint) SchedulableResult {
+	result := SchedulableResult{
+		ResourceReference: ref,
+		Tolerations:       tolerations,
+		EligibleNodes:     EligibleNodes(tolerations, nodeTaints),
+		IneligibleNodes:   make([]TaintsResult, 0),
+	}
+
+	for _, node := range TaintsResults(nodeTaints) {
+		untolerated := UntoleratedTaints(tolerations, node.Taints)
+		if len(untolerated) > 0 {
+			result.IneligibleNodes = append(result.IneligibleNodes, TaintsResult{
+				ResourceReference: node.ResourceReference,
+				Taints:            untolerated,
+			})
+		}
+	}
+	return result
+}
+
+// GroupByTaintSet groups nodes by their taints, sorted by taint set key
+func GroupByTaintSet(nodeTaints map[ResourceReference][]v1.Taint) []TaintSet {
+	groups := make(map[string]*TaintSet)
+	for node, taints := range nodeTaints {
+		key := TaintSetKey(taints)
+		if _, ok := groups[key]; !ok {
+			groups[key] = &TaintSet{
+				Key:    key,
+				Taints: sortedTaints(taints),
+				Nodes:  make([]ResourceReference, 0),
+			}
+		}
+		groups[key].Nodes = append(groups[key].Nodes, node)
+	}
+
+	sets := make([]TaintSet, 0, len(groups))
+	for _, set := range groups {
+		sort.Slice(set.Nodes, func(i, j int) bool {
+			return set.Nodes[i].Name < set.Nodes[j].Name
+		})
+		sets = append(sets, *set)
+	}
+	sort.Slice(sets, func(i, j int) bool {
+		return sets[i].Key < sets[j].Key
+	})
+	return sets
+}
+
+// TaintSetKey returns a key which is identical for equal sets of taints regardless of order
+func TaintSetKey(taints []v1.Taint) string {
+	keys := make([]string, 0, len(taints))
+	for _, taint := range sortedTaints(taints) {
+		keys = append(keys, taint.ToString())
+	}
+	return strings.Join(keys, ",")
+}
+
+func sortedTaints(taints []v1.Taint) []v1.Taint {
+	sorted := make([]v1.Taint, len(taints))
+	copy(sorted, taints)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ToString() < sorted[j].ToString()
+	})
+	return sorted
+}
@@ -0,0 +1,86 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestTolerates(t *testing.T) {
+	tests := []struct {
+		Description string
+		Tolerations []v1.Toleration
+		Taints      []v1.Taint
+		Expected    bool
+	}{
+		{
+			Description: "no taints",
+			Expected:    true,
+		},
+		{
+			Description: "equal toleration",
+			Tolerations: []v1.Toleration{_toleration("Equal", "app", "web", "NoSchedule")},
+			Taints:      []v1.Taint{_taint("app", "web", "NoSchedule")},
+			Expected:    true,
+		},
+		{
+			Description: "value mismatch",
+			Tolerations: []v1.Toleration{_toleration("Equal", "app", "db", "NoSchedule")},
+			Taints:      []v1.Taint{_taint("app", "web", "NoSchedule")},
+			Expected:    false,
+		},
+		{
+			Description: "exists toleration without effect",
+			Tolerations: []v1.Toleration{_toleration("Exists", "app", "", "")},
+			Taints:      []v1.Taint{_taint("app", "web", "NoSchedule"), _taint("app", "web", "NoExecute")},
+			Expected:    true,
+		},
+		{
+			Description: "prefer no schedule does not prevent scheduling",
+			Taints:      []v1.Taint{_taint("app", "web", "PreferNoSchedule")},
+			Expected:    true,
+		},
+		{
+			Description: "one of two taints not tolerated",
+			Tolerations: []v1.Toleration{_toleration("Equal", "app", "web", "NoSchedule")},
+			Taints:      []v1.Taint{_taint("app", "web", "NoSchedule"), _taint("gpu", "true", "NoSchedule")},
+			Expected:    false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		assert.Equal(t, test.Expected, Tolerates(test.Tolerations, test.Taints))
+	}
+}
+
+func TestGroupByTaintSet(t *testing.T) {
+	nodeTaints := map[ResourceReference][]v1.Taint{
+		_resourceReference("", "node-a", "Node"): {_taint("app", "web", "NoSchedule"), _taint("gpu", "", "NoSchedule")},
+		_resourceReference("", "node-b", "Node"): {_taint("gpu", "", "NoSchedule"), _taint("app", "web", "NoSchedule")},
+		_resourceReference("", "node-c", "Node"): {},
+	}
+
+	sets := GroupByTaintSet(nodeTaints)
+	assert.Len(t, sets, 2)
+	assert.Equal(t, "", sets[0].Key)
+	assert.Equal(t, []ResourceReference{_resourceReference("", "node-c", "Node")}, sets[0].Nodes)
+	assert.Equal(t, "app=web:NoSchedule,gpu:NoSchedule", sets[1].Key)
+	assert.Equal(t, []ResourceReference{
+		_resourceReference("", "node-a", "Node"),
+		_resourceReference("", "node-b", "Node"),
+	}, sets[1].Nodes)
+}
@@ -0,0 +1,170 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Sort keys of SortTaintsResults and SortTolerationsResults, taint-count counts taints or
+// tolerations and key is the lowest taint or toleration key
+const (
+	SortNamespace  = "namespace"
+	SortName       = "name"
+	SortKind       = "kind"
+	SortTaintCount = "taint-count"
+	SortKey        = "key"
+)
+
+// SortKeys are the valid sort keys
+var SortKeys = []string{SortNamespace, SortName, SortKind, SortTaintCount, SortKey}
+
+// sortFields are the values a result is sorted by
+type sortFields struct {
+	ref   ResourceReference
+	count int
+	key   string
+}
+
+// ValidateSortKeys returns an error if any of the keys is not a valid sort key
+func ValidateSortKeys(keys []string) error {
+	for _, key := range keys {
+		valid := false
+		for _, k := range SortKeys {
+			if key == k {
+				valid = true
+			}
+		}
+		if !valid {
+			return errors.Errorf("invalid sort key %v, must be one of %v", key, SortKeys)
+		}
+	}
+	return nil
+}
+
+// SortTaintsResults sorts results by the sort keys, ties are broken by namespace, kind and name
+func SortTaintsResults(results []TaintsResult, keys []string, reverse bool) error {
+	if err := ValidateSortKeys(keys); err != nil {
+		return err
+	}
+
+	fields := make([]sortFields, len(results))
+	for i, result := range results {
+		fields[i] = sortFields{ref: result.ResourceReference, count: len(result.Taints), key: lowestTaintKey(result.Taints)}
+	}
+	sort.Sort(&sorter{
+		fields:  fields,
+		keys:    keys,
+		reverse: reverse,
+		swap:    func(i, j int) { results[i], results[j] = results[j], results[i] },
+	})
+	return nil
+}
+
+// SortTolerationsResults sorts results by the sort keys, ties are broken by namespace, kind and name
+func SortTolerationsResults(results []TolerationsResult, keys []string, reverse bool) error {
+	if err := ValidateSortKeys(keys); err != nil {
+		return err
+	}
+
+	fields := make([]sortFields, len(results))
+	for i, result := range results {
+		fields[i] = sortFields{ref: result.ResourceReference, count: len(result.Tolerations), key: lowestTolerationKey(result.Tolerations)}
+	}
+	sort.Sort(&sorter{
+		fields:  fields,
+		keys:    keys,
+		reverse: reverse,
+		swap:    func(i, j int) { results[i], results[j] = results[j], results[i] },
+	})
+	return nil
+}
+
+// sorter sorts results along with their sort fields
+type sorter struct {
+	fields  []sortFields
+	keys    []string
+	reverse bool
+	swap    func(i, j int)
+}
+
+func (s *sorter) Len() int {
+	return len(s.fields)
+}
+
+func (s *sorter) Swap(i, j int) {
+	s.fields[i], s.fields[j] = s.fields[j], s.fields[i]
+	s.swap(i, j)
+}
+
+func (s *sorter) Less(i, j int) bool {
+	if s.reverse {
+		return lessFields(s.fields[j], s.fields[i], s.keys)
+	}
+	return lessFields(s.fields[i], s.fields[j], s.keys)
+}
+
+func lessFields(a, b sortFields, keys []string) bool {
+	for _, key := range keys {
+		switch key {
+		case SortNamespace:
+			if a.ref.Namespace != b.ref.Namespace {
+				return a.ref.Namespace < b.ref.Namespace
+			}
+		case SortName:
+			if a.ref.Name != b.ref.Name {
+				return a.ref.Name < b.ref.Name
+			}
+		case SortKind:
+			if a.ref.Kind != b.ref.Kind {
+				return a.ref.Kind < b.ref.Kind
+			}
+		case SortTaintCount:
+			if a.count != b.count {
+				return a.count < b.count
+			}
+		case SortKey:
+			if a.key != b.key {
+				return a.key < b.key
+			}
+		}
+	}
+	return LessReference(a.ref, b.ref)
+}
+
+func lowestTaintKey(taints []v1.Taint) string {
+	keys := make([]string, 0, len(taints))
+	for _, t := range taints {
+		keys = append(keys, t.Key)
+	}
+	return lowest(keys)
+}
+
+func lowestTolerationKey(tolerations []v1.Toleration) string {
+	keys := make([]string, 0, len(tolerations))
+	for _, t := range tolerations {
+		keys = append(keys, t.Key)
+	}
+	return lowest(keys)
+}
+
+func lowest(keys []string) string {
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+	return keys[0]
+}
@@ -0,0 +1,99 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestSortTolerationsResults(t *testing.T) {
+	results := func() []TolerationsResult {
+		return []TolerationsResult{
+			{ResourceReference: _resourceReference("web", "nginx", "Deployment"), Tolerations: []v1.Toleration{_toleration("Equal", "app", "web", "NoSchedule")}},
+			{ResourceReference: _resourceReference("db", "mysql", "StatefulSet"), Tolerations: []v1.Toleration{_toleration("Equal", "app", "db", "NoSchedule"), _toleration("Exists", "gpu", "", "")}},
+			{ResourceReference: _resourceReference("web", "apache", "Deployment"), Tolerations: []v1.Toleration{_toleration("Exists", "zone", "", "")}},
+			{ResourceReference: _resourceReference("web", "apache", "DaemonSet"), Tolerations: []v1.Toleration{}},
+		}
+	}
+
+	tests := []struct {
+		Description string
+		Keys        []string
+		Reverse     bool
+		Expected    []string
+		ExpectError bool
+	}{
+		{
+			Description: "namespace breaks ties by kind and name",
+			Keys:        []string{SortNamespace},
+			Expected:    []string{"db/StatefulSet/mysql", "web/DaemonSet/apache", "web/Deployment/apache", "web/Deployment/nginx"},
+		},
+		{
+			Description: "name then kind",
+			Keys:        []string{SortName, SortKind},
+			Expected:    []string{"web/DaemonSet/apache", "web/Deployment/apache", "db/StatefulSet/mysql", "web/Deployment/nginx"},
+		},
+		{
+			Description: "taint count",
+			Keys:        []string{SortTaintCount},
+			Expected:    []string{"web/DaemonSet/apache", "web/Deployment/apache", "web/Deployment/nginx", "db/StatefulSet/mysql"},
+		},
+		{
+			Description: "lowest key",
+			Keys:        []string{SortKey},
+			Expected:    []string{"web/DaemonSet/apache", "db/StatefulSet/mysql", "web/Deployment/nginx", "web/Deployment/apache"},
+		},
+		{
+			Description: "reverse",
+			Keys:        []string{SortNamespace, SortName},
+			Reverse:     true,
+			Expected:    []string{"web/Deployment/nginx", "web/Deployment/apache", "web/DaemonSet/apache", "db/StatefulSet/mysql"},
+		},
+		{
+			Description: "invalid key",
+			Keys:        []string{"age"},
+			ExpectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		sorted := results()
+		err := SortTolerationsResults(sorted, test.Keys, test.Reverse)
+		if test.ExpectError {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+
+		refs := make([]string, 0, len(sorted))
+		for _, result := range sorted {
+			refs = append(refs, result.Namespace+"/"+result.Kind+"/"+result.Name)
+		}
+		assert.Equal(t, test.Expected, refs)
+	}
+}
+
+func TestSortTaintsResults(t *testing.T) {
+	results := []TaintsResult{
+		{ResourceReference: _resourceReference("", "node-b", "Node"), Taints: []v1.Taint{_taint("app", "web", "NoSchedule")}},
+		{ResourceReference: _resourceReference("", "node-c", "Node"), Taints: []v1.Taint{}},
+		{ResourceReference: _resourceReference("", "node-a", "Node"), Taints: []v1.Taint{_taint("zone", "a", "NoSchedule"), _taint("gpu", "", "NoSchedule")}},
+	}
+
+	assert.NoError(t, SortTaintsResults(results, []string{SortTaintCount}, true))
+	assert.Equal(t, []string{"node-a", "node-b", "node-c"}, []string{results[0].Name, results[1].Name, results[2].Name})
+}
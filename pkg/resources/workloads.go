@@ -0,0 +1,114 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	PodGVR         = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	DeploymentGVR  = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	StatefulSetGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"}
+	DaemonSetGVR   = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"}
+	JobGVR         = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+	CronJobGVR     = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "cronjobs"}
+
+	// WorkloadGVRs are the resources inspected when tolerations are listed for all workloads
+	WorkloadGVRs = []schema.GroupVersionResource{
+		DeploymentGVR,
+		StatefulSetGVR,
+		DaemonSetGVR,
+		JobGVR,
+		CronJobGVR,
+	}
+
+	// TolerationPaths holds toleration paths for resources which do not use TolerationPath
+	TolerationPaths = map[schema.GroupVersionResource][]string{
+		PodGVR:     {"spec", "tolerations"},
+		CronJobGVR: {"spec", "jobTemplate", "spec", "template", "spec", "tolerations"},
+	}
+)
+
+// TolerationPathFor returns the path to the pod tolerations of a resource
+func TolerationPathFor(gvr schema.GroupVersionResource) []string {
+	if path, ok := TolerationPaths[gvr]; ok {
+		return path
+	}
+	return TolerationPath
+}
+
+// PodSpecPathFor returns the path to the pod spec of a resource
+func PodSpecPathFor(gvr schema.GroupVersionResource) []string {
+	path := TolerationPathFor(gvr)
+	return path[:len(path)-1]
+}
+
+// PodSpecFromObject converts the pod spec found at path
+func PodSpecFromObject(obj *unstructured.Unstructured, path []string) (v1.PodSpec, error) {
+	var spec v1.PodSpec
+	res, ok, err := unstructured.NestedMap(obj.Object, path...)
+	if !ok || err != nil {
+		return spec, err
+	}
+
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(res, &spec)
+	return spec, err
+}
+
+// ListWorkloadTolerations lists the tolerations of all WorkloadGVRs, resources which are not
+// served by the cluster are skipped and RuntimeClasses are listed once for all of them
+func ListWorkloadTolerations(client dynamic.Interface, namespace string) (map[ResourceReference][]v1.Toleration, error) {
+	tolerations, specs, err := ListWorkloadTemplates(client, namespace)
+	if err != nil {
+		return tolerations, err
+	}
+
+	runtimeClasses, err := ListRuntimeClassTolerations(client, specs)
+	if err != nil {
+		return tolerations, err
+	}
+	return MergeRuntimeClassTolerations(tolerations, specs, runtimeClasses), nil
+}
+
+// ListWorkloadTemplates is ListResourceTemplates for all WorkloadGVRs, resources which are not
+// served by the cluster are skipped
+func ListWorkloadTemplates(client dynamic.Interface, namespace string) (map[ResourceReference][]v1.Toleration, map[ResourceReference]v1.PodSpec, error) {
+	var (
+		tolerations = make(map[ResourceReference][]v1.Toleration)
+		specs       = make(map[ResourceReference]v1.PodSpec)
+	)
+
+	for _, gvr := range WorkloadGVRs {
+		resourceTolerations, resourceSpecs, err := ListResourceTemplates(client, gvr, namespace)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return tolerations, specs, err
+		}
+
+		for ref, tols := range resourceTolerations {
+			tolerations[ref] = tols
+		}
+		for ref, spec := range resourceSpecs {
+			specs[ref] = spec
+		}
+	}
+	return tolerations, specs, nil
+}
@@ -0,0 +1,196 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/eytan-avisror/ttsum/pkg/taints"
+	"github.com/eytan-avisror/ttsum/pkg/tolerations"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Source provides the taints and tolerations served by the API, usually backed by an informer cache
+type Source interface {
+	NodeTaints() (map[resources.ResourceReference][]v1.Taint, error)
+	ResourceTolerations(gvr schema.GroupVersionResource, namespace string) (map[resources.ResourceReference][]v1.Toleration, error)
+	WorkloadTolerations(namespace string) (map[resources.ResourceReference][]v1.Toleration, error)
+	Workloads() []schema.GroupVersionResource
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server serves taint and toleration queries as JSON
+type Server struct {
+	source Source
+	mux    *http.ServeMux
+}
+
+func New(source Source) *Server {
+	s := &Server{
+		source: source,
+		mux:    http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/v1/taints", s.handleTaints)
+	s.mux.HandleFunc("/v1/tolerations", s.handleTolerations)
+	s.mux.HandleFunc("/v1/schedulable", s.handleSchedulable)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleTaints serves /v1/taints?match=key=value:effect
+func (s *Server) handleTaints(w http.ResponseWriter, r *http.Request) {
+	nodeTaints, err := s.source.NodeTaints()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if match := r.URL.Query().Get("match"); match != "" {
+		expr, err := taints.Parse(match)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		nodeTaints = resources.FilterTaints(nodeTaints, expr, true)
+	}
+
+	writeJSON(w, resources.TaintsResults(nodeTaints))
+}
+
+// handleTolerations serves /v1/tolerations?gvr=apps/v1/deployments&namespace=x&match=Operator(key=value:effect),
+// all workloads are returned when gvr is omitted
+func (s *Server) handleTolerations(w http.ResponseWriter, r *http.Request) {
+	var (
+		query               = r.URL.Query()
+		resourceTolerations map[resources.ResourceReference][]v1.Toleration
+		err                 error
+	)
+
+	if gvr := query.Get("gvr"); gvr != "" {
+		parsed, parseErr := parseGVR(gvr)
+		if parseErr != nil {
+			writeError(w, http.StatusBadRequest, parseErr)
+			return
+		}
+		if !s.serves(parsed) {
+			writeError(w, http.StatusBadRequest, errors.Errorf("resource %v is not served, supported resources: %v", formatGVR(parsed), s.supported()))
+			return
+		}
+		resourceTolerations, err = s.source.ResourceTolerations(parsed, query.Get("namespace"))
+	} else {
+		resourceTolerations, err = s.source.WorkloadTolerations(query.Get("namespace"))
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if match := query.Get("match"); match != "" {
+		expr, err := tolerations.Parse(match)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		resourceTolerations = resources.FilterTolerations(resourceTolerations, expr, true)
+	}
+
+	writeJSON(w, resources.TolerationsResults(resourceTolerations))
+}
+
+// handleSchedulable serves /v1/schedulable?workload=kind/namespace/name
+func (s *Server) handleSchedulable(w http.ResponseWriter, r *http.Request) {
+	ref, err := resources.ParseReference(r.URL.Query().Get("workload"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	workloadTolerations, err := s.source.WorkloadTolerations(ref.Namespace)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	nodeTaints, err := s.source.NodeTaints()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	for workload, tols := range workloadTolerations {
+		if workload.Matches(ref) {
+			writeJSON(w, resources.Schedulable(workload, tols, nodeTaints))
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, errors.Errorf("workload %v not found", r.URL.Query().Get("workload")))
+}
+
+func (s *Server) serves(gvr schema.GroupVersionResource) bool {
+	for _, w := range s.source.Workloads() {
+		if w == gvr {
+			return true
+		}
+	}
+	return false
+}
+
+// supported lists the served resources in the gvr query format
+func (s *Server) supported() string {
+	gvrs := make([]string, 0)
+	for _, w := range s.source.Workloads() {
+		gvrs = append(gvrs, formatGVR(w))
+	}
+	return strings.Join(gvrs, ", ")
+}
+
+// parseGVR parses group/version/resource, or version/resource for the core group
+func parseGVR(s string) (schema.GroupVersionResource, error) {
+	i := strings.LastIndex(s, "/")
+	if i <= 0 || i == len(s)-1 {
+		return schema.GroupVersionResource{}, errors.Errorf("invalid gvr: %v, must be in format group/version/resource", s)
+	}
+	return resources.Parse(s[:i], s[i+1:]), nil
+}
+
+// formatGVR is the inverse of parseGVR
+func formatGVR(gvr schema.GroupVersionResource) string {
+	return gvr.GroupVersion().String() + "/" + gvr.Resource
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,162 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/eytan-avisror/ttsum/pkg/cache"
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestServer(t *testing.T) {
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		resources.NodeGVR:         "NodeList",
+		resources.RuntimeClassGVR: "RuntimeClassList",
+		resources.DeploymentGVR:   "DeploymentList",
+		resources.StatefulSetGVR:  "StatefulSetList",
+		resources.DaemonSetGVR:    "DaemonSetList",
+		resources.JobGVR:          "JobList",
+		resources.CronJobGVR:      "CronJobList",
+	},
+		_unstructured("v1", "Node", "", "node-a", resources.TaintPath, _taint("app", "web")),
+		_unstructured("v1", "Node", "", "node-b", resources.TaintPath, _taint("app", "db")),
+		_unstructured("apps/v1", "Deployment", "default", "nginx", resources.TolerationPath, _toleration("app", "web")),
+		_unstructured("apps/v1", "Deployment", "other", "mysql", resources.TolerationPath, _toleration("app", "db")),
+	)
+
+	c, err := cache.New(client, "", time.Minute)
+	assert.NoError(t, err)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	assert.NoError(t, c.Start(stopCh))
+
+	tests := []struct {
+		Description  string
+		Path         string
+		ExpectedCode int
+		Result       interface{}
+		Expected     interface{}
+	}{
+		{
+			Description:  "taints with match",
+			Path:         "/v1/taints?match=app=web:NoSchedule",
+			ExpectedCode: http.StatusOK,
+			Result:       &[]resources.TaintsResult{},
+			Expected: &[]resources.TaintsResult{
+				{ResourceReference: resources.ResourceReference{Name: "node-a", Kind: "Node"}, Taints: []v1.Taint{_taint("app", "web")}},
+			},
+		},
+		{
+			Description:  "tolerations by gvr and namespace",
+			Path:         "/v1/tolerations?gvr=apps/v1/deployments&namespace=default",
+			ExpectedCode: http.StatusOK,
+			Result:       &[]resources.TolerationsResult{},
+			Expected: &[]resources.TolerationsResult{
+				{ResourceReference: resources.ResourceReference{Namespace: "default", Name: "nginx", Kind: "Deployment"}, Tolerations: []v1.Toleration{_toleration("app", "web")}},
+			},
+		},
+		{
+			Description:  "schedulable workload",
+			Path:         "/v1/schedulable?workload=deployment/other/mysql",
+			ExpectedCode: http.StatusOK,
+			Result:       &resources.SchedulableResult{},
+			Expected: &resources.SchedulableResult{
+				ResourceReference: resources.ResourceReference{Namespace: "other", Name: "mysql", Kind: "Deployment"},
+				Tolerations:       []v1.Toleration{_toleration("app", "db")},
+				EligibleNodes:     []resources.ResourceReference{{Name: "node-b", Kind: "Node"}},
+				IneligibleNodes: []resources.TaintsResult{
+					{ResourceReference: resources.ResourceReference{Name: "node-a", Kind: "Node"}, Taints: []v1.Taint{_taint("app", "web")}},
+				},
+			},
+		},
+		{
+			Description:  "unknown workload",
+			Path:         "/v1/schedulable?workload=deployment/other/redis",
+			ExpectedCode: http.StatusNotFound,
+		},
+		{
+			Description:  "invalid gvr",
+			Path:         "/v1/tolerations?gvr=deployments",
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Description:  "unsupported gvr",
+			Path:         "/v1/tolerations?gvr=v1/pods",
+			ExpectedCode: http.StatusBadRequest,
+			Result:       &errorResponse{},
+			Expected:     &errorResponse{Error: "resource v1/pods is not served, supported resources: apps/v1/deployments, apps/v1/statefulsets, apps/v1/daemonsets, batch/v1/jobs, batch/v1/cronjobs"},
+		},
+	}
+
+	server := New(c)
+	for _, test := range tests {
+		t.Log(test.Description)
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.Path, nil))
+		assert.Equal(t, test.ExpectedCode, recorder.Code)
+		if test.Result != nil {
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), test.Result))
+			assert.Equal(t, test.Expected, test.Result)
+		}
+	}
+}
+
+func _toleration(key, value string) v1.Toleration {
+	return v1.Toleration{
+		Operator: v1.TolerationOpEqual,
+		Key:      key,
+		Value:    value,
+		Effect:   v1.TaintEffectNoSchedule,
+	}
+}
+
+func _taint(key, value string) v1.Taint {
+	return v1.Taint{
+		Key:    key,
+		Value:  value,
+		Effect: v1.TaintEffectNoSchedule,
+	}
+}
+
+func _unstructured(apiVersion, kind, namespace, name string, path []string, objs ...interface{}) *unstructured.Unstructured {
+	base := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"namespace": namespace,
+				"name":      name,
+			},
+		},
+	}
+
+	items := make([]interface{}, 0)
+	for _, o := range objs {
+		item, _ := runtime.DefaultUnstructuredConverter.ToUnstructured(&o)
+		items = append(items, item)
+	}
+
+	unstructured.SetNestedField(base.Object, items, path...)
+	return base
+}
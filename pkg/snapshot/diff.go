@@ -0,0 +1,358 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"sort"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	v1 "k8s.io/api/core/v1"
+)
+
+type Change string
+
+const (
+	ChangeAdded    Change = "added"
+	ChangeRemoved  Change = "removed"
+	ChangeModified Change = "modified"
+)
+
+// Diff holds the differences between two snapshots
+type Diff struct {
+	Nodes       []TaintDiff       `json:"nodes"`
+	Workloads   []TolerationDiff  `json:"workloads"`
+	TaintSets   []TaintSetDiff    `json:"taintSets"`
+	Eligibility []EligibilityDiff `json:"eligibility"`
+}
+
+// TaintDiff describes how the taints of a node changed, taints with the same key and effect
+// but a different value are reported as changed
+type TaintDiff struct {
+	resources.ResourceReference
+	Change  Change        `json:"change"`
+	Added   []v1.Taint    `json:"added,omitempty"`
+	Removed []v1.Taint    `json:"removed,omitempty"`
+	Changed []TaintChange `json:"changed,omitempty"`
+}
+
+type TaintChange struct {
+	From v1.Taint `json:"from"`
+	To   v1.Taint `json:"to"`
+}
+
+// TolerationDiff describes how the tolerations of a workload changed, tolerations with the same
+// key and effect but a different operator, value or seconds are reported as changed
+type TolerationDiff struct {
+	resources.ResourceReference
+	Change  Change             `json:"change"`
+	Added   []v1.Toleration    `json:"added,omitempty"`
+	Removed []v1.Toleration    `json:"removed,omitempty"`
+	Changed []TolerationChange `json:"changed,omitempty"`
+}
+
+type TolerationChange struct {
+	From v1.Toleration `json:"from"`
+	To   v1.Toleration `json:"to"`
+}
+
+// TaintSetDiff describes a change in the number of nodes sharing a set of taints
+type TaintSetDiff struct {
+	Key    string     `json:"key"`
+	Taints []v1.Taint `json:"taints"`
+	Before int        `json:"before"`
+	After  int        `json:"after"`
+}
+
+// EligibilityDiff describes the taint sets a workload can or can no longer be scheduled on
+type EligibilityDiff struct {
+	resources.ResourceReference
+	Gained []string `json:"gained,omitempty"`
+	Lost   []string `json:"lost,omitempty"`
+	Before int      `json:"before"`
+	After  int      `json:"after"`
+}
+
+// Compare returns the differences between snapshot a and snapshot b
+func Compare(a, b *Snapshot) Diff {
+	var (
+		aTaints      = a.NodeTaints()
+		bTaints      = b.NodeTaints()
+		aTolerations = a.WorkloadTolerations()
+		bTolerations = b.WorkloadTolerations()
+	)
+
+	return Diff{
+		Nodes:       compareTaints(aTaints, bTaints),
+		Workloads:   compareTolerations(aTolerations, bTolerations),
+		TaintSets:   compareTaintSets(aTaints, bTaints),
+		Eligibility: compareEligibility(aTaints, bTaints, aTolerations, bTolerations),
+	}
+}
+
+// Empty returns true if there are no differences
+func (d Diff) Empty() bool {
+	return len(d.Nodes) == 0 && len(d.Workloads) == 0 && len(d.TaintSets) == 0 && len(d.Eligibility) == 0
+}
+
+func compareTaints(a, b map[resources.ResourceReference][]v1.Taint) []TaintDiff {
+	diffs := make([]TaintDiff, 0)
+	for _, ref := range unionReferences(taintReferences(a), taintReferences(b)) {
+		before, inA := a[ref]
+		after, inB := b[ref]
+
+		diff := TaintDiff{ResourceReference: ref}
+		switch {
+		case !inA:
+			diff.Change = ChangeAdded
+			diff.Added = after
+		case !inB:
+			diff.Change = ChangeRemoved
+			diff.Removed = before
+		default:
+			diff.Change = ChangeModified
+			diff.Added, diff.Removed, diff.Changed = diffTaints(before, after)
+			if len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0 {
+				continue
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+func compareTolerations(a, b map[resources.ResourceReference][]v1.Toleration) []TolerationDiff {
+	diffs := make([]TolerationDiff, 0)
+	for _, ref := range unionReferences(tolerationReferences(a), tolerationReferences(b)) {
+		before, inA := a[ref]
+		after, inB := b[ref]
+
+		diff := TolerationDiff{ResourceReference: ref}
+		switch {
+		case !inA:
+			diff.Change = ChangeAdded
+			diff.Added = after
+		case !inB:
+			diff.Change = ChangeRemoved
+			diff.Removed = before
+		default:
+			diff.Change = ChangeModified
+			diff.Added, diff.Removed, diff.Changed = diffTolerations(before, after)
+			if len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0 {
+				continue
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+func compareTaintSets(a, b map[resources.ResourceReference][]v1.Taint) []TaintSetDiff {
+	var (
+		sets  = make(map[string]*TaintSetDiff)
+		diffs = make([]TaintSetDiff, 0)
+	)
+
+	for _, set := range resources.GroupByTaintSet(a) {
+		sets[set.Key] = &TaintSetDiff{Key: set.Key, Taints: set.Taints, Before: len(set.Nodes)}
+	}
+	for _, set := range resources.GroupByTaintSet(b) {
+		if _, ok := sets[set.Key]; !ok {
+			sets[set.Key] = &TaintSetDiff{Key: set.Key, Taints: set.Taints}
+		}
+		sets[set.Key].After = len(set.Nodes)
+	}
+
+	for _, set := range sets {
+		if set.Before != set.After {
+			diffs = append(diffs, *set)
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Key < diffs[j].Key
+	})
+	return diffs
+}
+
+func compareEligibility(aTaints, bTaints map[resources.ResourceReference][]v1.Taint, aTolerations, bTolerations map[resources.ResourceReference][]v1.Toleration) []EligibilityDiff {
+	var (
+		aSets = resources.GroupByTaintSet(aTaints)
+		bSets = resources.GroupByTaintSet(bTaints)
+		diffs = make([]EligibilityDiff, 0)
+	)
+
+	for _, ref := range unionReferences(tolerationReferences(aTolerations), tolerationReferences(bTolerations)) {
+		before, inA := aTolerations[ref]
+		after, inB := bTolerations[ref]
+		if !inA || !inB {
+			continue
+		}
+
+		beforeSets, beforeNodes := eligibleTaintSets(before, aSets)
+		afterSets, afterNodes := eligibleTaintSets(after, bSets)
+
+		diff := EligibilityDiff{
+			ResourceReference: ref,
+			Before:            beforeNodes,
+			After:             afterNodes,
+		}
+		for key := range afterSets {
+			if !beforeSets[key] {
+				diff.Gained = append(diff.Gained, key)
+			}
+		}
+		for key := range beforeSets {
+			if !afterSets[key] {
+				diff.Lost = append(diff.Lost, key)
+			}
+		}
+		if len(diff.Gained) == 0 && len(diff.Lost) == 0 {
+			continue
+		}
+		sort.Strings(diff.Gained)
+		sort.Strings(diff.Lost)
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+func eligibleTaintSets(tolerations []v1.Toleration, sets []resources.TaintSet) (map[string]bool, int) {
+	var (
+		eligible = make(map[string]bool)
+		nodes    int
+	)
+	for _, set := range sets {
+		if resources.Tolerates(tolerations, set.Taints) {
+			eligible[set.Key] = true
+			nodes += len(set.Nodes)
+		}
+	}
+	return eligible, nodes
+}
+
+func diffTaints(before, after []v1.Taint) (added, removed []v1.Taint, changed []TaintChange) {
+	removed = subtractTaints(before, after)
+	added = subtractTaints(after, before)
+
+	for i := 0; i < len(removed); i++ {
+		for j := range added {
+			if removed[i].MatchTaint(&added[j]) {
+				changed = append(changed, TaintChange{From: removed[i], To: added[j]})
+				removed = append(removed[:i], removed[i+1:]...)
+				added = append(added[:j], added[j+1:]...)
+				i--
+				break
+			}
+		}
+	}
+	return added, removed, changed
+}
+
+func diffTolerations(before, after []v1.Toleration) (added, removed []v1.Toleration, changed []TolerationChange) {
+	removed = subtractTolerations(before, after)
+	added = subtractTolerations(after, before)
+
+	for i := 0; i < len(removed); i++ {
+		for j := range added {
+			if removed[i].Key == added[j].Key && removed[i].Effect == added[j].Effect {
+				changed = append(changed, TolerationChange{From: removed[i], To: added[j]})
+				removed = append(removed[:i], removed[i+1:]...)
+				added = append(added[:j], added[j+1:]...)
+				i--
+				break
+			}
+		}
+	}
+	return added, removed, changed
+}
+
+// subtractTaints returns the taints in a which are not in b, ignoring the time they were added
+func subtractTaints(a, b []v1.Taint) []v1.Taint {
+	var (
+		result = make([]v1.Taint, 0)
+		used   = make([]bool, len(b))
+	)
+	for _, x := range a {
+		var found bool
+		for j, y := range b {
+			if !used[j] && x.Key == y.Key && x.Value == y.Value && x.Effect == y.Effect {
+				used[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, x)
+		}
+	}
+	return result
+}
+
+// subtractTolerations returns the tolerations in a which are not in b
+func subtractTolerations(a, b []v1.Toleration) []v1.Toleration {
+	var (
+		result = make([]v1.Toleration, 0)
+		used   = make([]bool, len(b))
+	)
+	for _, x := range a {
+		var found bool
+		for j, y := range b {
+			if !used[j] && x.MatchToleration(&y) && equalSeconds(x.TolerationSeconds, y.TolerationSeconds) {
+				used[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, x)
+		}
+	}
+	return result
+}
+
+func equalSeconds(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func taintReferences(objs map[resources.ResourceReference][]v1.Taint) []resources.ResourceReference {
+	refs := make([]resources.ResourceReference, 0, len(objs))
+	for ref := range objs {
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+func tolerationReferences(objs map[resources.ResourceReference][]v1.Toleration) []resources.ResourceReference {
+	refs := make([]resources.ResourceReference, 0, len(objs))
+	for ref := range objs {
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+func unionReferences(a, b []resources.ResourceReference) []resources.ResourceReference {
+	var (
+		seen  = make(map[resources.ResourceReference]bool)
+		union = make([]resources.ResourceReference, 0)
+	)
+	for _, ref := range append(a, b...) {
+		if !seen[ref] {
+			seen[ref] = true
+			union = append(union, ref)
+		}
+	}
+	resources.SortReferences(union)
+	return union
+}
@@ -0,0 +1,137 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestCompare(t *testing.T) {
+	before := &Snapshot{
+		Nodes: []resources.TaintsResult{
+			_node("node-a", _taint("app", "web", "NoSchedule")),
+			_node("node-b", _taint("app", "db", "NoSchedule")),
+		},
+		Workloads: []resources.TolerationsResult{
+			_workload("default", "nginx", _toleration("Equal", "app", "web", "NoSchedule")),
+			_workload("default", "mysql", _toleration("Equal", "app", "db", "NoSchedule")),
+		},
+	}
+	after := &Snapshot{
+		Nodes: []resources.TaintsResult{
+			_node("node-a", _taint("app", "api", "NoSchedule")),
+			_node("node-c", _taint("app", "db", "NoSchedule")),
+		},
+		Workloads: []resources.TolerationsResult{
+			_workload("default", "nginx", _toleration("Equal", "app", "web", "NoSchedule")),
+			_workload("default", "mysql", _toleration("Exists", "app", "", "NoSchedule")),
+		},
+	}
+
+	diff := Compare(before, after)
+
+	assert.Equal(t, []TaintDiff{
+		{
+			ResourceReference: _reference("", "node-a", "Node"),
+			Change:            ChangeModified,
+			Added:             []v1.Taint{},
+			Removed:           []v1.Taint{},
+			Changed:           []TaintChange{{From: _taint("app", "web", "NoSchedule"), To: _taint("app", "api", "NoSchedule")}},
+		},
+		{
+			ResourceReference: _reference("", "node-b", "Node"),
+			Change:            ChangeRemoved,
+			Removed:           []v1.Taint{_taint("app", "db", "NoSchedule")},
+		},
+		{
+			ResourceReference: _reference("", "node-c", "Node"),
+			Change:            ChangeAdded,
+			Added:             []v1.Taint{_taint("app", "db", "NoSchedule")},
+		},
+	}, diff.Nodes)
+
+	assert.Len(t, diff.Workloads, 1)
+	assert.Equal(t, "mysql", diff.Workloads[0].Name)
+	assert.Equal(t, []TolerationChange{{From: _toleration("Equal", "app", "db", "NoSchedule"), To: _toleration("Exists", "app", "", "NoSchedule")}}, diff.Workloads[0].Changed)
+
+	assert.Equal(t, []TaintSetDiff{
+		{Key: "app=api:NoSchedule", Taints: []v1.Taint{_taint("app", "api", "NoSchedule")}, Before: 0, After: 1},
+		{Key: "app=web:NoSchedule", Taints: []v1.Taint{_taint("app", "web", "NoSchedule")}, Before: 1, After: 0},
+	}, diff.TaintSets)
+
+	assert.Equal(t, []EligibilityDiff{
+		{
+			ResourceReference: _reference("default", "mysql", "Deployment"),
+			Gained:            []string{"app=api:NoSchedule"},
+			Before:            1,
+			After:             2,
+		},
+		{
+			ResourceReference: _reference("default", "nginx", "Deployment"),
+			Lost:              []string{"app=web:NoSchedule"},
+			Before:            1,
+			After:             0,
+		},
+	}, diff.Eligibility)
+}
+
+func TestCompareIdentical(t *testing.T) {
+	s := &Snapshot{
+		Nodes:     []resources.TaintsResult{_node("node-a", _taint("app", "web", "NoSchedule"))},
+		Workloads: []resources.TolerationsResult{_workload("default", "nginx", _toleration("Equal", "app", "web", "NoSchedule"))},
+	}
+	assert.True(t, Compare(s, s).Empty())
+}
+
+func _node(name string, taints ...v1.Taint) resources.TaintsResult {
+	return resources.TaintsResult{
+		ResourceReference: _reference("", name, "Node"),
+		Taints:            taints,
+	}
+}
+
+func _workload(namespace, name string, tolerations ...v1.Toleration) resources.TolerationsResult {
+	return resources.TolerationsResult{
+		ResourceReference: _reference(namespace, name, "Deployment"),
+		Tolerations:       tolerations,
+	}
+}
+
+func _reference(namespace, name, kind string) resources.ResourceReference {
+	return resources.ResourceReference{
+		Namespace: namespace,
+		Name:      name,
+		Kind:      kind,
+	}
+}
+
+func _toleration(operator, key, value, effect string) v1.Toleration {
+	return v1.Toleration{
+		Operator: v1.TolerationOperator(operator),
+		Key:      key,
+		Value:    value,
+		Effect:   v1.TaintEffect(effect),
+	}
+}
+
+func _taint(key, value, effect string) v1.Taint {
+	return v1.Taint{
+		Key:    key,
+		Value:  value,
+		Effect: v1.TaintEffect(effect),
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+// Snapshot is a point in time capture of node taints and workload tolerations
+type Snapshot struct {
+	Source     string                        `json:"source,omitempty"`
+	CapturedAt metav1.Time                   `json:"capturedAt"`
+	Nodes      []resources.TaintsResult      `json:"nodes"`
+	Workloads  []resources.TolerationsResult `json:"workloads"`
+}
+
+// Capture lists node taints and workload tolerations from a cluster
+func Capture(client dynamic.Interface, namespace, source string) (*Snapshot, error) {
+	nodeTaints, err := resources.ListNodeTaints(client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list node taints")
+	}
+
+	workloadTolerations, err := resources.ListWorkloadTolerations(client, namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list workload tolerations")
+	}
+
+	return &Snapshot{
+		Source:     source,
+		CapturedAt: metav1.Now(),
+		Nodes:      resources.TaintsResults(nodeTaints),
+		Workloads:  resources.TolerationsResults(workloadTolerations),
+	}, nil
+}
+
+// Load reads a snapshot previously written with Write
+func Load(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var s Snapshot
+	if err := json.NewDecoder(f).Decode(&s); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode snapshot %v", path)
+	}
+	if s.Source == "" {
+		s.Source = path
+	}
+	return &s, nil
+}
+
+// Write encodes the snapshot as JSON
+func (s *Snapshot) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// NodeTaints returns the snapshot node taints keyed by node
+func (s *Snapshot) NodeTaints() map[resources.ResourceReference][]v1.Taint {
+	return resources.TaintsMap(s.Nodes)
+}
+
+// WorkloadTolerations returns the snapshot workload tolerations keyed by workload
+func (s *Snapshot) WorkloadTolerations() map[resources.ResourceReference][]v1.Toleration {
+	return resources.TolerationsMap(s.Workloads)
+}
@@ -0,0 +1,82 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taints
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// Class classifies who manages a taint
+type Class string
+
+const (
+	// ClassSystem taints are managed by Kubernetes components to reflect node conditions and roles
+	ClassSystem Class = "system"
+	// ClassLifecycle taints are managed by cloud providers and autoscalers while nodes are created or removed
+	ClassLifecycle Class = "lifecycle"
+	// ClassUser taints dedicate nodes to workloads which tolerate them
+	ClassUser Class = "user"
+)
+
+// Entry describes a well-known taint, an empty Value matches any value
+type Entry struct {
+	Key         string `json:"key"`
+	Value       string `json:"value,omitempty"`
+	Class       Class  `json:"class"`
+	Explanation string `json:"explanation"`
+}
+
+// Catalog holds well-known taints
+var Catalog = []Entry{
+	{Key: v1.TaintNodeNotReady, Class: ClassSystem, Explanation: "node is not ready"},
+	{Key: v1.TaintNodeUnreachable, Class: ClassSystem, Explanation: "node controller cannot reach the node"},
+	{Key: v1.TaintNodeUnschedulable, Class: ClassSystem, Explanation: "node is cordoned"},
+	{Key: v1.TaintNodeMemoryPressure, Class: ClassSystem, Explanation: "node is low on memory"},
+	{Key: v1.TaintNodeDiskPressure, Class: ClassSystem, Explanation: "node is low on disk space"},
+	{Key: v1.TaintNodePIDPressure, Class: ClassSystem, Explanation: "node is low on process IDs"},
+	{Key: v1.TaintNodeNetworkUnavailable, Class: ClassSystem, Explanation: "node network is not configured"},
+	{Key: "node-role.kubernetes.io/control-plane", Class: ClassSystem, Explanation: "node runs the control plane"},
+	{Key: "node-role.kubernetes.io/master", Class: ClassSystem, Explanation: "node runs the control plane (deprecated key)"},
+	{Key: v1.TaintNodeOutOfService, Class: ClassLifecycle, Explanation: "node was shut down, pods are force deleted"},
+	{Key: "node.cloudprovider.kubernetes.io/uninitialized", Class: ClassLifecycle, Explanation: "cloud controller manager has not initialized the node"},
+	{Key: "node.cloudprovider.kubernetes.io/shutdown", Class: ClassLifecycle, Explanation: "cloud provider reports the node is shut down"},
+	{Key: "ToBeDeletedByClusterAutoscaler", Class: ClassLifecycle, Explanation: "cluster-autoscaler is removing the node"},
+	{Key: "DeletionCandidateOfClusterAutoscaler", Class: ClassLifecycle, Explanation: "cluster-autoscaler considers the node unneeded"},
+	{Key: "karpenter.sh/disruption", Class: ClassLifecycle, Explanation: "karpenter is disrupting the node"},
+	{Key: "karpenter.sh/unregistered", Class: ClassLifecycle, Explanation: "node has not registered with karpenter"},
+	{Key: "nvidia.com/gpu", Class: ClassUser, Explanation: "node has NVIDIA GPUs"},
+	{Key: "amd.com/gpu", Class: ClassUser, Explanation: "node has AMD GPUs"},
+	{Key: "gpu.intel.com/i915", Class: ClassUser, Explanation: "node has Intel GPUs"},
+	{Key: "sku", Value: "gpu", Class: ClassUser, Explanation: "AKS node pool has GPUs"},
+}
+
+// Explain returns the catalog entry of a taint, taints which are not in the catalog are user taints
+func Explain(t v1.Taint) Entry {
+	for _, e := range Catalog {
+		if e.Key == t.Key && (e.Value == "" || e.Value == t.Value) {
+			return e
+		}
+	}
+	return Entry{Key: t.Key, Value: t.Value, Class: ClassUser}
+}
+
+// UserOnly returns the taints of class user
+func UserOnly(taints []v1.Taint) []v1.Taint {
+	filtered := make([]v1.Taint, 0, len(taints))
+	for _, t := range taints {
+		if Explain(t).Class == ClassUser {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,75 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taints
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		Description         string
+		Taint               v1.Taint
+		ExpectedClass       Class
+		ExpectedExplanation string
+	}{
+		{
+			Description:         "node condition taint",
+			Taint:               v1.Taint{Key: v1.TaintNodeNotReady, Effect: v1.TaintEffectNoExecute},
+			ExpectedClass:       ClassSystem,
+			ExpectedExplanation: "node is not ready",
+		},
+		{
+			Description:         "cluster-autoscaler taint",
+			Taint:               v1.Taint{Key: "ToBeDeletedByClusterAutoscaler", Value: "1700000000", Effect: v1.TaintEffectNoSchedule},
+			ExpectedClass:       ClassLifecycle,
+			ExpectedExplanation: "cluster-autoscaler is removing the node",
+		},
+		{
+			Description:         "value specific entry",
+			Taint:               v1.Taint{Key: "sku", Value: "gpu", Effect: v1.TaintEffectNoSchedule},
+			ExpectedClass:       ClassUser,
+			ExpectedExplanation: "AKS node pool has GPUs",
+		},
+		{
+			Description:   "value specific entry with another value",
+			Taint:         v1.Taint{Key: "sku", Value: "cpu", Effect: v1.TaintEffectNoSchedule},
+			ExpectedClass: ClassUser,
+		},
+		{
+			Description:   "unknown taint",
+			Taint:         v1.Taint{Key: "app", Value: "web", Effect: v1.TaintEffectNoSchedule},
+			ExpectedClass: ClassUser,
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		entry := Explain(test.Taint)
+		assert.Equal(t, test.ExpectedClass, entry.Class)
+		assert.Equal(t, test.ExpectedExplanation, entry.Explanation)
+	}
+}
+
+func TestUserOnly(t *testing.T) {
+	ts := []v1.Taint{
+		{Key: v1.TaintNodeUnschedulable, Effect: v1.TaintEffectNoSchedule},
+		{Key: "node-role.kubernetes.io/control-plane", Effect: v1.TaintEffectNoSchedule},
+		{Key: "karpenter.sh/disruption", Value: "disrupting", Effect: v1.TaintEffectNoSchedule},
+		{Key: "app", Value: "web", Effect: v1.TaintEffectNoSchedule},
+	}
+	assert.Equal(t, []v1.Taint{{Key: "app", Value: "web", Effect: v1.TaintEffectNoSchedule}}, UserOnly(ts))
+}
@@ -15,12 +15,22 @@ package taints
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
 )
 
+// Separator is the default separator between taints printed by PrintPretty
+const Separator = ",\n"
+
 func PrintPretty(taints []v1.Taint) string {
+	return PrintPrettySeparated(taints, Separator)
+}
+
+// PrintPrettySeparated prints taints like PrintPretty, separated by sep
+func PrintPrettySeparated(taints []v1.Taint, sep string) string {
 	var result string
 	taintCount := len(taints)
 	if taintCount == 0 {
@@ -38,13 +48,31 @@ func PrintPretty(taints []v1.Taint) string {
 			res += fmt.Sprintf(":%v", t.Effect)
 		}
 		if i < taintCount-1 {
-			res += ",\n"
+			res += sep
 		}
 		result += res
 	}
 	return result
 }
 
+// PrintPrettyWithAge prints taints like PrintPrettySeparated, followed by how long ago they were added
+// when TimeAdded is set
+func PrintPrettyWithAge(taints []v1.Taint, now time.Time, sep string) string {
+	if len(taints) == 0 {
+		return PrintPretty(taints)
+	}
+
+	lines := make([]string, 0, len(taints))
+	for _, t := range taints {
+		line := PrintPretty([]v1.Taint{t})
+		if t.TimeAdded != nil {
+			line += fmt.Sprintf(" (%v)", duration.HumanDuration(now.Sub(t.TimeAdded.Time)))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, sep)
+}
+
 func Parse(t string) (v1.Taint, error) {
 
 	var (
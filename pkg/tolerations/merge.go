@@ -0,0 +1,102 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tolerations
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// IsSuperset returns true if ss tolerates every taint that t tolerates, this follows the
+// semantics of the PodTolerationRestriction admission plugin
+func IsSuperset(ss, t v1.Toleration) bool {
+	if ss.MatchToleration(&t) && equalSeconds(ss.TolerationSeconds, t.TolerationSeconds) {
+		return true
+	}
+
+	if ss.Key != "" && ss.Key != t.Key {
+		return false
+	}
+	if ss.Key == "" && ss.Operator != v1.TolerationOpExists {
+		return false
+	}
+
+	if ss.Effect != "" && ss.Effect != t.Effect {
+		return false
+	}
+
+	// a toleration which leaves a NoExecute taint sooner does not cover one which stays longer
+	if ss.Effect == v1.TaintEffectNoExecute && ss.TolerationSeconds != nil {
+		if t.TolerationSeconds == nil || *t.TolerationSeconds > *ss.TolerationSeconds {
+			return false
+		}
+	}
+
+	switch ss.Operator {
+	case v1.TolerationOpEqual, "":
+		return (t.Operator == v1.TolerationOpEqual || t.Operator == "") && ss.Value == t.Value
+	case v1.TolerationOpExists:
+		return true
+	default:
+		return false
+	}
+}
+
+// Merge returns first merged with second, tolerations which are covered by another are dropped
+// and the order of first is preserved
+func Merge(first, second []v1.Toleration) []v1.Toleration {
+	all := append(append(make([]v1.Toleration, 0, len(first)+len(second)), first...), second...)
+
+	merged := make([]v1.Toleration, 0, len(all))
+	for i, t := range all {
+		var covered bool
+		for j, other := range all {
+			if i == j {
+				continue
+			}
+			// keep the first of two identical tolerations
+			if IsSuperset(other, t) && (!IsSuperset(t, other) || j < i) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			merged = append(merged, t)
+		}
+	}
+	return merged
+}
+
+// NotWhitelisted returns the tolerations which are not covered by any whitelisted toleration
+func NotWhitelisted(tolerations, whitelist []v1.Toleration) []v1.Toleration {
+	rejected := make([]v1.Toleration, 0)
+	for _, t := range tolerations {
+		var allowed bool
+		for _, w := range whitelist {
+			if IsSuperset(w, t) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			rejected = append(rejected, t)
+		}
+	}
+	return rejected
+}
+
+func equalSeconds(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
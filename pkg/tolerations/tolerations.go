@@ -20,7 +20,15 @@ import (
 	v1 "k8s.io/api/core/v1"
 )
 
+// Separator is the default separator between tolerations printed by PrintPretty
+const Separator = ",\n"
+
 func PrintPretty(tolerations []v1.Toleration) string {
+	return PrintPrettySeparated(tolerations, Separator)
+}
+
+// PrintPrettySeparated prints tolerations like PrintPretty, separated by sep
+func PrintPrettySeparated(tolerations []v1.Toleration, sep string) string {
 	var result string
 
 	tolCount := len(tolerations)
@@ -38,10 +46,9 @@ func PrintPretty(tolerations []v1.Toleration) string {
 		if t.Effect != "" {
 			res += fmt.Sprintf(":%v", t.Effect)
 		}
+		res += ")"
 		if i < tolCount-1 {
-			res += "),\n"
-		} else {
-			res += ")"
+			res += sep
 		}
 		result += res
 	}
@@ -59,12 +66,13 @@ func Parse(t string) (v1.Toleration, error) {
 	// equal is the default
 	toleration.Operator = v1.TolerationOpEqual
 
+	inner = t
 	i := strings.Index(t, "(")
 	if i >= 0 {
 		j := strings.Index(t, ")")
 		if j >= 0 {
 			inner = t[i+1 : j]
-			outer = t[0 : i-1]
+			outer = t[0:i]
 
 			if strings.EqualFold(outer, string(v1.TolerationOpExists)) {
 				toleration.Operator = v1.TolerationOpExists
@@ -72,8 +80,9 @@ func Parse(t string) (v1.Toleration, error) {
 		}
 	}
 
-	if inner == "" {
-		inner = t
+	// Exists() tolerates everything
+	if inner == "" && toleration.Operator == v1.TolerationOpExists {
+		return toleration, nil
 	}
 
 	split := strings.Split(inner, ":")
@@ -0,0 +1,155 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tolerations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		Description string
+		Input       string
+		Expected    v1.Toleration
+		ExpectError bool
+	}{
+		{
+			Description: "equal with value and effect",
+			Input:       "Equal(app=web:NoSchedule)",
+			Expected:    _toleration("Equal", "app", "web", "NoSchedule"),
+		},
+		{
+			Description: "exists with effect",
+			Input:       "Exists(nvidia.com/gpu:NoSchedule)",
+			Expected:    _toleration("Exists", "nvidia.com/gpu", "", "NoSchedule"),
+		},
+		{
+			Description: "exists without effect",
+			Input:       "Exists(nvidia.com/gpu)",
+			Expected:    _toleration("Exists", "nvidia.com/gpu", "", ""),
+		},
+		{
+			Description: "exists everything",
+			Input:       "Exists()",
+			Expected:    _toleration("Exists", "", "", ""),
+		},
+		{
+			Description: "operator defaults to equal",
+			Input:       "app=web",
+			Expected:    _toleration("Equal", "app", "web", ""),
+		},
+		{
+			Description: "invalid effect",
+			Input:       "Equal(app=web:NoRun)",
+			ExpectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		toleration, err := Parse(test.Input)
+		if test.ExpectError {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, test.Expected, toleration)
+	}
+}
+
+func TestPrintPrettySeparated(t *testing.T) {
+	tests := []struct {
+		Description string
+		Tolerations []v1.Toleration
+		Separator   string
+		Expected    string
+	}{
+		{
+			Description: "no tolerations",
+			Separator:   "; ",
+			Expected:    "none",
+		},
+		{
+			Description: "default separator",
+			Tolerations: []v1.Toleration{_toleration("Equal", "app", "web", "NoSchedule"), _toleration("Exists", "gpu", "", "")},
+			Separator:   Separator,
+			Expected:    "Equal(app=web:NoSchedule),\nExists(gpu)",
+		},
+		{
+			Description: "markdown separator",
+			Tolerations: []v1.Toleration{_toleration("Equal", "app", "web", "NoSchedule"), _toleration("Exists", "gpu", "", "")},
+			Separator:   "<br>",
+			Expected:    "Equal(app=web:NoSchedule)<br>Exists(gpu)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		assert.Equal(t, test.Expected, PrintPrettySeparated(test.Tolerations, test.Separator))
+	}
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		Description string
+		First       []v1.Toleration
+		Second      []v1.Toleration
+		Expected    []v1.Toleration
+	}{
+		{
+			Description: "disjoint tolerations are appended",
+			First:       []v1.Toleration{_toleration("Equal", "app", "web", "NoSchedule")},
+			Second:      []v1.Toleration{_toleration("Exists", "gpu", "", "NoSchedule")},
+			Expected:    []v1.Toleration{_toleration("Equal", "app", "web", "NoSchedule"), _toleration("Exists", "gpu", "", "NoSchedule")},
+		},
+		{
+			Description: "duplicates are dropped",
+			First:       []v1.Toleration{_toleration("Equal", "app", "web", "NoSchedule")},
+			Second:      []v1.Toleration{_toleration("Equal", "app", "web", "NoSchedule")},
+			Expected:    []v1.Toleration{_toleration("Equal", "app", "web", "NoSchedule")},
+		},
+		{
+			Description: "subsets are dropped",
+			First:       []v1.Toleration{_toleration("Equal", "app", "web", "NoSchedule")},
+			Second:      []v1.Toleration{_toleration("Exists", "app", "", "")},
+			Expected:    []v1.Toleration{_toleration("Exists", "app", "", "")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		assert.Equal(t, test.Expected, Merge(test.First, test.Second))
+	}
+}
+
+func TestNotWhitelisted(t *testing.T) {
+	whitelist := []v1.Toleration{_toleration("Exists", "app", "", ""), _toleration("Equal", "gpu", "true", "NoSchedule")}
+	tols := []v1.Toleration{
+		_toleration("Equal", "app", "web", "NoSchedule"),
+		_toleration("Equal", "gpu", "true", "NoSchedule"),
+		_toleration("Exists", "gpu", "", "NoSchedule"),
+	}
+	assert.Equal(t, []v1.Toleration{_toleration("Exists", "gpu", "", "NoSchedule")}, NotWhitelisted(tols, whitelist))
+}
+
+func _toleration(operator, key, value, effect string) v1.Toleration {
+	return v1.Toleration{
+		Operator: v1.TolerationOperator(operator),
+		Key:      key,
+		Value:    value,
+		Effect:   v1.TaintEffect(effect),
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/eytan-avisror/ttsum/pkg/taints"
+	"github.com/eytan-avisror/ttsum/pkg/tolerations"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Source provides node taints and workload tolerations
+type Source interface {
+	NodeTaints() (map[resources.ResourceReference][]v1.Taint, error)
+	WorkloadTolerations(namespace string) (map[resources.ResourceReference][]v1.Toleration, error)
+}
+
+// Model holds the taint sets and workloads shown by the UI
+type Model struct {
+	TaintSets []resources.TaintSet
+	Workloads []resources.TolerationsResult
+}
+
+// Load reads a model from a source
+func Load(source Source, namespace string) (*Model, error) {
+	nodeTaints, err := source.NodeTaints()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list node taints")
+	}
+
+	workloadTolerations, err := source.WorkloadTolerations(namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list workload tolerations")
+	}
+
+	return &Model{
+		TaintSets: resources.GroupByTaintSet(nodeTaints),
+		Workloads: resources.TolerationsResults(workloadTolerations),
+	}, nil
+}
+
+// FilterTaintSets returns the taint sets with a taint or node containing filter, or tolerated by a
+// workload containing filter, case insensitive
+func (m *Model) FilterTaintSets(filter string) []resources.TaintSet {
+	if filter == "" {
+		return m.TaintSets
+	}
+
+	sets := make([]resources.TaintSet, 0)
+	for _, set := range m.TaintSets {
+		if setMatches(set, filter) || len(m.Tolerating(set, filter)) > 0 {
+			sets = append(sets, set)
+		}
+	}
+	return sets
+}
+
+// Tolerating returns the workloads which tolerate the taints of a set, when filter is not contained
+// in the taints or nodes of the set only workloads containing filter are returned, case insensitive
+func (m *Model) Tolerating(set resources.TaintSet, filter string) []resources.TolerationsResult {
+	if setMatches(set, filter) {
+		filter = ""
+	}
+
+	workloads := make([]resources.TolerationsResult, 0)
+	for _, w := range m.Workloads {
+		if resources.Tolerates(w.Tolerations, set.Taints) && contains(WorkloadTitle(w), filter) {
+			workloads = append(workloads, w)
+		}
+	}
+	return workloads
+}
+
+// setMatches returns true if a taint or node of a set contains filter
+func setMatches(set resources.TaintSet, filter string) bool {
+	if contains(set.Key, filter) {
+		return true
+	}
+	for _, node := range set.Nodes {
+		if contains(node.Name, filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// TaintSetTitle returns the list title of a taint set
+func TaintSetTitle(set resources.TaintSet) string {
+	title := taints.PrintPrettySeparated(set.Taints, ", ")
+	return fmt.Sprintf("%v (%v)", title, len(set.Nodes))
+}
+
+// WorkloadTitle returns the list title of a workload
+func WorkloadTitle(w resources.TolerationsResult) string {
+	return fmt.Sprintf("%v/%v/%v", strings.ToLower(w.Kind), w.Namespace, w.Name)
+}
+
+// Describe prints every toleration of a workload, including tolerationSeconds
+func Describe(w resources.TolerationsResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v\n\n", WorkloadTitle(w))
+	if len(w.Tolerations) == 0 {
+		b.WriteString("no tolerations\n")
+	}
+	for _, t := range w.Tolerations {
+		seconds := "-"
+		switch {
+		case t.TolerationSeconds != nil:
+			seconds = fmt.Sprintf("%vs", *t.TolerationSeconds)
+		case t.Effect == v1.TaintEffectNoExecute || t.Effect == "":
+			seconds = "forever"
+		}
+		effect := string(t.Effect)
+		if effect == "" {
+			effect = "any"
+		}
+		fmt.Fprintf(&b, "%v\n  effect: %v, tolerationSeconds: %v\n", tolerations.PrintPretty([]v1.Toleration{t}), effect, seconds)
+	}
+	return b.String()
+}
+
+func contains(s, filter string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(filter))
+}
@@ -0,0 +1,111 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ui
+
+import (
+	"testing"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+type _source struct {
+	nodeTaints  map[resources.ResourceReference][]v1.Taint
+	tolerations map[resources.ResourceReference][]v1.Toleration
+}
+
+func (s *_source) NodeTaints() (map[resources.ResourceReference][]v1.Taint, error) {
+	return s.nodeTaints, nil
+}
+
+func (s *_source) WorkloadTolerations(namespace string) (map[resources.ResourceReference][]v1.Toleration, error) {
+	return s.tolerations, nil
+}
+
+func TestModel(t *testing.T) {
+	source := &_source{
+		nodeTaints: map[resources.ResourceReference][]v1.Taint{
+			{Name: "db-1", Kind: "Node"}:  {{Key: "app", Value: "db", Effect: v1.TaintEffectNoSchedule}},
+			{Name: "web-1", Kind: "Node"}: {{Key: "app", Value: "web", Effect: v1.TaintEffectNoSchedule}},
+		},
+		tolerations: map[resources.ResourceReference][]v1.Toleration{
+			{Namespace: "default", Name: "mysql", Kind: "Deployment"}: {{Key: "app", Operator: v1.TolerationOpEqual, Value: "db", Effect: v1.TaintEffectNoSchedule}},
+			{Namespace: "default", Name: "nginx", Kind: "Deployment"}: {{Key: "app", Operator: v1.TolerationOpEqual, Value: "web", Effect: v1.TaintEffectNoSchedule}},
+		},
+	}
+
+	model, err := Load(source, "")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		Description       string
+		Filter            string
+		ExpectedTaintSets []string
+		ExpectedWorkloads []string
+	}{
+		{
+			Description:       "no filter",
+			ExpectedTaintSets: []string{"app=db:NoSchedule (1)", "app=web:NoSchedule (1)"},
+			ExpectedWorkloads: []string{"deployment/default/mysql"},
+		},
+		{
+			Description:       "filter on a node",
+			Filter:            "WEB-1",
+			ExpectedTaintSets: []string{"app=web:NoSchedule (1)"},
+			ExpectedWorkloads: []string{"deployment/default/nginx"},
+		},
+		{
+			Description:       "filter on a workload",
+			Filter:            "nginx",
+			ExpectedTaintSets: []string{"app=web:NoSchedule (1)"},
+			ExpectedWorkloads: []string{"deployment/default/nginx"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		sets := model.FilterTaintSets(test.Filter)
+
+		titles := make([]string, 0)
+		for _, set := range sets {
+			titles = append(titles, TaintSetTitle(set))
+		}
+		assert.Equal(t, test.ExpectedTaintSets, titles)
+
+		workloads := make([]string, 0)
+		for _, w := range model.Tolerating(sets[0], test.Filter) {
+			workloads = append(workloads, WorkloadTitle(w))
+		}
+		assert.Equal(t, test.ExpectedWorkloads, workloads)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	var seconds int64 = 300
+	w := resources.TolerationsResult{
+		ResourceReference: resources.ResourceReference{Namespace: "default", Name: "nginx", Kind: "Deployment"},
+		Tolerations: []v1.Toleration{
+			{Key: "app", Operator: v1.TolerationOpEqual, Value: "web", Effect: v1.TaintEffectNoSchedule},
+			{Key: v1.TaintNodeNotReady, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute, TolerationSeconds: &seconds},
+		},
+	}
+
+	assert.Equal(t, `deployment/default/nginx
+
+Equal(app=web:NoSchedule)
+  effect: NoSchedule, tolerationSeconds: -
+Exists(node.kubernetes.io/not-ready:NoExecute)
+  effect: NoExecute, tolerationSeconds: 300s
+`, Describe(w))
+}
@@ -0,0 +1,183 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ui
+
+import (
+	"fmt"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const help = "[yellow]tab[white] next pane  [yellow]/[white] filter  [yellow]enter[white] tolerations  [yellow]r[white] refresh  [yellow]q[white] quit"
+
+// UI browses taint sets, their nodes and the workloads tolerating them
+type UI struct {
+	source    Source
+	namespace string
+
+	app       *tview.Application
+	filter    *tview.InputField
+	taintSets *tview.List
+	nodes     *tview.List
+	workloads *tview.List
+	details   *tview.TextView
+	status    *tview.TextView
+
+	model     *Model
+	sets      []resources.TaintSet
+	tolerated []resources.TolerationsResult
+}
+
+// New builds a UI showing the workloads of namespace, all namespaces when empty
+func New(source Source, namespace string) *UI {
+	u := &UI{
+		source:    source,
+		namespace: namespace,
+		app:       tview.NewApplication(),
+		filter:    tview.NewInputField().SetLabel("/ "),
+		taintSets: tview.NewList().ShowSecondaryText(false),
+		nodes:     tview.NewList().ShowSecondaryText(false),
+		workloads: tview.NewList().ShowSecondaryText(false),
+		details:   tview.NewTextView().SetScrollable(true),
+		status:    tview.NewTextView().SetDynamicColors(true).SetText(help),
+	}
+
+	u.taintSets.SetBorder(true).SetTitle(" Taint sets ")
+	u.nodes.SetBorder(true).SetTitle(" Nodes ")
+	u.workloads.SetBorder(true).SetTitle(" Tolerating workloads ")
+	u.details.SetBorder(true).SetTitle(" Tolerations ")
+
+	u.taintSets.SetChangedFunc(func(i int, _, _ string, _ rune) { u.selectTaintSet(i) })
+	u.workloads.SetChangedFunc(func(i int, _, _ string, _ rune) { u.selectWorkload(i) })
+	u.workloads.SetSelectedFunc(func(i int, _, _ string, _ rune) {
+		u.selectWorkload(i)
+		u.app.SetFocus(u.details)
+	})
+	u.filter.SetChangedFunc(func(string) { u.render() })
+	u.filter.SetDoneFunc(func(tcell.Key) { u.app.SetFocus(u.taintSets) })
+
+	panes := tview.NewFlex().
+		AddItem(u.taintSets, 0, 2, true).
+		AddItem(u.nodes, 0, 1, false).
+		AddItem(u.workloads, 0, 2, false)
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(u.filter, 1, 0, false).
+		AddItem(panes, 0, 3, true).
+		AddItem(u.details, 0, 1, false).
+		AddItem(u.status, 1, 0, false)
+
+	u.app.SetRoot(layout, true).SetInputCapture(u.handleKey)
+	return u
+}
+
+// Refresh reloads the model from the source, it is safe to call from other goroutines once Run is called
+func (u *UI) Refresh() {
+	model, err := Load(u.source, u.namespace)
+	u.app.QueueUpdateDraw(func() {
+		if err != nil {
+			u.status.SetText(fmt.Sprintf("[red]%v[white]  %v", err, help))
+			return
+		}
+		u.model = model
+		u.status.SetText(help)
+		u.render()
+	})
+}
+
+// Run loads the model and blocks until the UI is closed
+func (u *UI) Run() error {
+	go u.Refresh()
+	return u.app.Run()
+}
+
+func (u *UI) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	if u.app.GetFocus() == u.filter {
+		return event
+	}
+
+	switch {
+	case event.Key() == tcell.KeyTab:
+		u.cycleFocus()
+		return nil
+	case event.Rune() == '/':
+		u.app.SetFocus(u.filter)
+		return nil
+	case event.Rune() == 'r':
+		go u.Refresh()
+		return nil
+	case event.Rune() == 'q':
+		u.app.Stop()
+		return nil
+	}
+	return event
+}
+
+func (u *UI) cycleFocus() {
+	order := []tview.Primitive{u.taintSets, u.nodes, u.workloads, u.details}
+	for i, p := range order {
+		if u.app.GetFocus() == p {
+			u.app.SetFocus(order[(i+1)%len(order)])
+			return
+		}
+	}
+	u.app.SetFocus(u.taintSets)
+}
+
+// render redraws the taint sets matching the filter, keeping the selection where possible
+func (u *UI) render() {
+	if u.model == nil {
+		return
+	}
+
+	current := u.taintSets.GetCurrentItem()
+	u.sets = u.model.FilterTaintSets(u.filter.GetText())
+
+	u.taintSets.Clear()
+	for _, set := range u.sets {
+		u.taintSets.AddItem(TaintSetTitle(set), "", 0, nil)
+	}
+	if current >= 0 && current < len(u.sets) {
+		u.taintSets.SetCurrentItem(current)
+	}
+	u.selectTaintSet(u.taintSets.GetCurrentItem())
+}
+
+func (u *UI) selectTaintSet(i int) {
+	u.nodes.Clear()
+	u.workloads.Clear()
+	u.details.Clear()
+	if i < 0 || i >= len(u.sets) {
+		return
+	}
+
+	set := u.sets[i]
+	for _, node := range set.Nodes {
+		u.nodes.AddItem(node.Name, "", 0, nil)
+	}
+
+	u.tolerated = u.model.Tolerating(set, u.filter.GetText())
+	for _, w := range u.tolerated {
+		u.workloads.AddItem(WorkloadTitle(w), "", 0, nil)
+	}
+	u.selectWorkload(u.workloads.GetCurrentItem())
+}
+
+func (u *UI) selectWorkload(i int) {
+	if i < 0 || i >= len(u.tolerated) {
+		u.details.Clear()
+		return
+	}
+	u.details.SetText(Describe(u.tolerated[i])).ScrollToBeginning()
+}
@@ -0,0 +1,111 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/eytan-avisror/ttsum/pkg/policy"
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/eytan-avisror/ttsum/pkg/tolerations"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Mutator merges the default tolerations of a namespace policy into pods and pod templates on creation,
+// and rejects tolerations which are not whitelisted for the namespace
+type Mutator struct {
+	Policy     *policy.Policy
+	Namespaces NamespaceGetter
+}
+
+func (m *Mutator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	serveReview(w, r, m)
+}
+
+func (m *Mutator) Review(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if !isCreateOrUpdate(req) {
+		return allowed()
+	}
+
+	obj, path, err := requestObject(req)
+	if err != nil {
+		return errored(err)
+	}
+
+	tols, err := resources.TolerationsFromObject(obj, path)
+	if err != nil {
+		return errored(err)
+	}
+
+	namespaceLabels, err := m.Namespaces.NamespaceLabels(req.Namespace)
+	if err != nil {
+		return errored(err)
+	}
+
+	defaults, whitelist, restricted := m.Policy.NamespaceTolerations(namespaceLabels)
+
+	merged := tols
+	if req.Operation == admissionv1.Create {
+		merged = tolerations.Merge(tols, defaults)
+	}
+
+	if restricted {
+		rejected := tolerations.NotWhitelisted(merged, whitelist)
+		if len(rejected) > 0 {
+			messages := make([]string, 0, len(rejected))
+			for _, t := range rejected {
+				messages = append(messages, fmt.Sprintf("toleration %v is not whitelisted in namespace %v",
+					tolerations.PrintPretty([]v1.Toleration{t}), req.Namespace))
+			}
+			return denied(http.StatusForbidden, strings.Join(messages, "; "))
+		}
+	}
+
+	if reflect.DeepEqual(tols, merged) {
+		return allowed()
+	}
+
+	patch, err := json.Marshal([]patchOperation{
+		{Op: "add", Path: jsonPointer(path), Value: merged},
+	})
+	if err != nil {
+		return errored(err)
+	}
+
+	patchType := admissionv1.PatchTypeJSONPatch
+	response := allowed()
+	response.Patch = patch
+	response.PatchType = &patchType
+	return response
+}
+
+// jsonPointer converts an object path into an RFC 6901 JSON pointer
+func jsonPointer(path []string) string {
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+	var pointer string
+	for _, p := range path {
+		pointer += "/" + escaper.Replace(p)
+	}
+	return pointer
+}
@@ -0,0 +1,85 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/eytan-avisror/ttsum/pkg/policy"
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+// Validator denies pods and pod templates whose tolerations violate a policy, in audit only mode
+// violations are returned as warnings and requests are allowed
+type Validator struct {
+	Policy     *policy.Policy
+	Namespaces NamespaceGetter
+	AuditOnly  bool
+}
+
+func (v *Validator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	serveReview(w, r, v)
+}
+
+func (v *Validator) Review(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if !isCreateOrUpdate(req) {
+		return allowed()
+	}
+
+	obj, path, err := requestObject(req)
+	if err != nil {
+		return v.errored(err)
+	}
+
+	tolerations, err := resources.TolerationsFromObject(obj, path)
+	if err != nil {
+		return v.errored(err)
+	}
+	if len(tolerations) == 0 {
+		return allowed()
+	}
+
+	namespaceLabels, err := v.Namespaces.NamespaceLabels(req.Namespace)
+	if err != nil {
+		return v.errored(err)
+	}
+
+	violations := v.Policy.Evaluate(req.Namespace, namespaceLabels, tolerations)
+	if len(violations) == 0 {
+		return allowed()
+	}
+
+	messages := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		messages = append(messages, violation.String())
+	}
+
+	if v.AuditOnly {
+		response := allowed()
+		response.Warnings = messages
+		return response
+	}
+	return denied(http.StatusForbidden, strings.Join(messages, "; "))
+}
+
+// errored never blocks admission in audit only mode, the error is returned as a warning instead
+func (v *Validator) errored(err error) *admissionv1.AdmissionResponse {
+	if v.AuditOnly {
+		response := allowed()
+		response.Warnings = []string{err.Error()}
+		return response
+	}
+	return errored(err)
+}
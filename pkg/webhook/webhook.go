@@ -0,0 +1,125 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/pkg/errors"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// NamespaceGetter returns the labels of a namespace
+type NamespaceGetter interface {
+	NamespaceLabels(name string) (map[string]string, error)
+}
+
+type dynamicNamespaceGetter struct {
+	client dynamic.Interface
+}
+
+// NewNamespaceGetter returns a NamespaceGetter which gets namespaces from the API
+func NewNamespaceGetter(client dynamic.Interface) NamespaceGetter {
+	return &dynamicNamespaceGetter{client: client}
+}
+
+func (g *dynamicNamespaceGetter) NamespaceLabels(name string) (map[string]string, error) {
+	ns, err := g.client.Resource(resources.NamespaceGVR).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return ns.GetLabels(), nil
+}
+
+// reviewer produces an admission response for a request
+type reviewer interface {
+	Review(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse
+}
+
+// serveReview decodes an AdmissionReview, reviews its request and writes back the response
+func serveReview(w http.ResponseWriter, r *http.Request, rev reviewer) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var review admissionv1.AdmissionReview
+	if err := json.Unmarshal(body, &review); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "admission review has no request", http.StatusBadRequest)
+		return
+	}
+
+	response := rev.Review(review.Request)
+	response.UID = review.Request.UID
+	review.Response = response
+	review.Request = nil
+
+	data, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+// requestObject decodes the object of a request and returns the path to its pod tolerations
+func requestObject(req *admissionv1.AdmissionRequest) (*unstructured.Unstructured, []string, error) {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(req.Object.Raw); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to decode object")
+	}
+
+	gvr := schema.GroupVersionResource{
+		Group:    req.Resource.Group,
+		Version:  req.Resource.Version,
+		Resource: req.Resource.Resource,
+	}
+	return obj, resources.TolerationPathFor(gvr), nil
+}
+
+func allowed() *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+func denied(code int32, message string) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+func errored(err error) *admissionv1.AdmissionResponse {
+	return denied(http.StatusInternalServerError, err.Error())
+}
+
+func isCreateOrUpdate(req *admissionv1.AdmissionRequest) bool {
+	return req.Operation == admissionv1.Create || req.Operation == admissionv1.Update
+}
@@ -0,0 +1,270 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/eytan-avisror/ttsum/pkg/policy"
+	"github.com/eytan-avisror/ttsum/pkg/resources"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type fakeNamespaces map[string]map[string]string
+
+func (f fakeNamespaces) NamespaceLabels(name string) (map[string]string, error) {
+	return f[name], nil
+}
+
+type failingNamespaces struct{}
+
+func (f failingNamespaces) NamespaceLabels(name string) (map[string]string, error) {
+	return nil, errors.Errorf("namespaces %q is forbidden", name)
+}
+
+const gpuPolicy = `
+rules:
+- name: gpu
+  taint: nvidia.com/gpu
+  namespaceSelector:
+    matchLabels:
+      tier: gpu
+`
+
+func TestValidator(t *testing.T) {
+	p, err := policy.Parse([]byte(gpuPolicy))
+	assert.NoError(t, err)
+
+	namespaces := fakeNamespaces{
+		"ml":    {"tier": "gpu"},
+		"batch": {"tier": "cpu"},
+	}
+
+	tests := []struct {
+		Description      string
+		Namespace        string
+		AuditOnly        bool
+		Tolerations      []v1.Toleration
+		ExpectedAllowed  bool
+		ExpectedMessage  string
+		ExpectedWarnings []string
+	}{
+		{
+			Description:     "gpu toleration in gpu namespace",
+			Namespace:       "ml",
+			Tolerations:     []v1.Toleration{{Operator: v1.TolerationOpExists, Key: "nvidia.com/gpu", Effect: v1.TaintEffectNoSchedule}},
+			ExpectedAllowed: true,
+		},
+		{
+			Description:     "gpu toleration in cpu namespace",
+			Namespace:       "batch",
+			Tolerations:     []v1.Toleration{{Operator: v1.TolerationOpExists, Key: "nvidia.com/gpu", Effect: v1.TaintEffectNoSchedule}},
+			ExpectedAllowed: false,
+			ExpectedMessage: "toleration Exists(nvidia.com/gpu:NoSchedule) is not allowed in namespace batch: only namespaces matching tier=gpu may tolerate nvidia.com/gpu (rule gpu)",
+		},
+		{
+			Description:     "wildcard toleration in cpu namespace",
+			Namespace:       "batch",
+			Tolerations:     []v1.Toleration{{Operator: v1.TolerationOpExists}},
+			ExpectedAllowed: false,
+			ExpectedMessage: "toleration Exists() is not allowed in namespace batch: only namespaces matching tier=gpu may tolerate nvidia.com/gpu (rule gpu)",
+		},
+		{
+			Description:     "unrelated toleration in cpu namespace",
+			Namespace:       "batch",
+			Tolerations:     []v1.Toleration{{Operator: v1.TolerationOpEqual, Key: "app", Value: "web", Effect: v1.TaintEffectNoSchedule}},
+			ExpectedAllowed: true,
+		},
+		{
+			Description:      "gpu toleration in cpu namespace audit only",
+			Namespace:        "batch",
+			AuditOnly:        true,
+			Tolerations:      []v1.Toleration{{Operator: v1.TolerationOpEqual, Key: "nvidia.com/gpu", Value: "true"}},
+			ExpectedAllowed:  true,
+			ExpectedWarnings: []string{"toleration Equal(nvidia.com/gpu=true) is not allowed in namespace batch: only namespaces matching tier=gpu may tolerate nvidia.com/gpu (rule gpu)"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		validator := &Validator{Policy: p, Namespaces: namespaces, AuditOnly: test.AuditOnly}
+		response := validator.Review(_deploymentRequest(test.Namespace, test.Tolerations...))
+		assert.Equal(t, test.ExpectedAllowed, response.Allowed)
+		assert.Equal(t, test.ExpectedWarnings, response.Warnings)
+		if test.ExpectedMessage != "" {
+			assert.Equal(t, test.ExpectedMessage, response.Result.Message)
+		}
+	}
+}
+
+func TestValidatorErrors(t *testing.T) {
+	p, err := policy.Parse([]byte(gpuPolicy))
+	assert.NoError(t, err)
+
+	undecodable := _deploymentRequest("ml")
+	undecodable.Object.Raw = []byte("{")
+
+	tests := []struct {
+		Description      string
+		Request          *admissionv1.AdmissionRequest
+		AuditOnly        bool
+		ExpectedAllowed  bool
+		ExpectedCode     int32
+		ExpectedWarnings int
+	}{
+		{
+			Description:  "undecodable object",
+			Request:      undecodable,
+			ExpectedCode: http.StatusInternalServerError,
+		},
+		{
+			Description:      "undecodable object audit only",
+			Request:          undecodable,
+			AuditOnly:        true,
+			ExpectedAllowed:  true,
+			ExpectedWarnings: 1,
+		},
+		{
+			Description:  "namespace lookup fails",
+			Request:      _deploymentRequest("ml", v1.Toleration{Operator: v1.TolerationOpExists}),
+			ExpectedCode: http.StatusInternalServerError,
+		},
+		{
+			Description:      "namespace lookup fails audit only",
+			Request:          _deploymentRequest("ml", v1.Toleration{Operator: v1.TolerationOpExists}),
+			AuditOnly:        true,
+			ExpectedAllowed:  true,
+			ExpectedWarnings: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		validator := &Validator{Policy: p, Namespaces: failingNamespaces{}, AuditOnly: test.AuditOnly}
+		response := validator.Review(test.Request)
+		assert.Equal(t, test.ExpectedAllowed, response.Allowed)
+		assert.Len(t, response.Warnings, test.ExpectedWarnings)
+		if !test.ExpectedAllowed {
+			assert.Equal(t, test.ExpectedCode, response.Result.Code)
+		}
+	}
+}
+
+const namespacePolicy = `
+namespaces:
+- name: gpu
+  namespaceSelector:
+    matchLabels:
+      tier: gpu
+  defaultTolerations:
+  - Exists(nvidia.com/gpu:NoSchedule)
+  whitelist:
+  - Exists(nvidia.com/gpu)
+  - Exists(app)
+`
+
+func TestMutator(t *testing.T) {
+	p, err := policy.Parse([]byte(namespacePolicy))
+	assert.NoError(t, err)
+
+	namespaces := fakeNamespaces{
+		"ml":    {"tier": "gpu"},
+		"batch": {"tier": "cpu"},
+	}
+
+	tests := []struct {
+		Description     string
+		Namespace       string
+		Tolerations     []v1.Toleration
+		ExpectedAllowed bool
+		ExpectedPatch   string
+		ExpectedMessage string
+	}{
+		{
+			Description:     "default toleration is added",
+			Namespace:       "ml",
+			Tolerations:     []v1.Toleration{{Operator: v1.TolerationOpEqual, Key: "app", Value: "web", Effect: v1.TaintEffectNoSchedule}},
+			ExpectedAllowed: true,
+			ExpectedPatch:   `[{"op":"add","path":"/spec/template/spec/tolerations","value":[{"key":"app","operator":"Equal","value":"web","effect":"NoSchedule"},{"key":"nvidia.com/gpu","operator":"Exists","effect":"NoSchedule"}]}]`,
+		},
+		{
+			Description:     "default toleration already present",
+			Namespace:       "ml",
+			Tolerations:     []v1.Toleration{{Operator: v1.TolerationOpExists, Key: "nvidia.com/gpu", Effect: v1.TaintEffectNoSchedule}},
+			ExpectedAllowed: true,
+		},
+		{
+			Description:     "toleration not whitelisted",
+			Namespace:       "ml",
+			Tolerations:     []v1.Toleration{{Operator: v1.TolerationOpEqual, Key: "dedicated", Value: "db", Effect: v1.TaintEffectNoSchedule}},
+			ExpectedAllowed: false,
+			ExpectedMessage: "toleration Equal(dedicated=db:NoSchedule) is not whitelisted in namespace ml",
+		},
+		{
+			Description:     "namespace without policy",
+			Namespace:       "batch",
+			Tolerations:     []v1.Toleration{{Operator: v1.TolerationOpEqual, Key: "dedicated", Value: "db", Effect: v1.TaintEffectNoSchedule}},
+			ExpectedAllowed: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.Description)
+		mutator := &Mutator{Policy: p, Namespaces: namespaces}
+		response := mutator.Review(_deploymentRequest(test.Namespace, test.Tolerations...))
+		assert.Equal(t, test.ExpectedAllowed, response.Allowed)
+		assert.Equal(t, test.ExpectedPatch, string(response.Patch))
+		if test.ExpectedMessage != "" {
+			assert.Equal(t, test.ExpectedMessage, response.Result.Message)
+		}
+	}
+}
+
+func _deploymentRequest(namespace string, tolerations ...v1.Toleration) *admissionv1.AdmissionRequest {
+	unstructuredTolerations := make([]interface{}, 0)
+	for _, t := range tolerations {
+		ts, _ := runtime.DefaultUnstructuredConverter.ToUnstructured(&t)
+		unstructuredTolerations = append(unstructuredTolerations, ts)
+	}
+
+	obj := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"namespace": namespace,
+			"name":      "app",
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"tolerations": unstructuredTolerations,
+				},
+			},
+		},
+	}
+	raw, _ := json.Marshal(obj)
+
+	return &admissionv1.AdmissionRequest{
+		Resource:  metav1.GroupVersionResource{Group: resources.DeploymentGVR.Group, Version: resources.DeploymentGVR.Version, Resource: resources.DeploymentGVR.Resource},
+		Namespace: namespace,
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: raw},
+	}
+}